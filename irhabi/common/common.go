@@ -95,23 +95,21 @@ func Rounder(num float64, intermed float64, precision int) float64 {
 	return round / pow
 }
 
-// Encrypt perform simple encryption and decription values.
+// Encrypt returns obfuscated value of the numeric id, see EncodeID.
+// Value that is not an integer returns empty string.
 func Encrypt(n interface{}) string {
-	num := ToInt(n)
-	return ToString(((0x0000FFFF & num) << 16) + ((0xFFFF0000 & num) >> 16))
+	id, err := strconv.ParseInt(Trim(ToString(n), ""), 10, 64)
+	if err != nil {
+		return ""
+	}
+
+	return EncodeID(id)
 }
 
-// Decrypt return real values of encripted values.
+// Decrypt return real values of encripted values,
+// modified values returns *DecryptionError, see DecodeID.
 func Decrypt(v interface{}) (id int64, err error) {
-	if num := ToInt(Encrypt(v)); num != 0 {
-		id = int64(num)
-		return id, nil
-	}
-
-	err = &DecryptionError{
-		Values: v,
-	}
-	return
+	return DecodeID(ToString(v))
 }
 
 // DecryptionError error type caused by decription failure.
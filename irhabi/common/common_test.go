@@ -5,38 +5,54 @@
 package common
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
 func TestEncrypt(t *testing.T) {
-	t.Parallel()
-
 	var tests = []struct {
 		param    interface{}
-		expected string
+		expected int64
 	}{
-		{1, "65536"},
-		{4040, "264765440"},
-		{"264765440", "4040"},
-		{"65536", "1"},
-		{"randomstring", "0"},
+		{1, 1},
+		{4040, 4040},
+		{"264765440", 264765440},
+		{int64(math.MaxInt64), math.MaxInt64},
 	}
 	for _, test := range tests {
-		assert.Equal(t, test.expected, Encrypt(test.param))
+		e := Encrypt(test.param)
+		assert.Len(t, e, 22)
+		assert.NotEqual(t, ToString(test.expected), e)
+
+		v, err := Decrypt(e)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, v)
 	}
+
+	assert.Equal(t, Encrypt(4040), Encrypt("4040"))
+	assert.NotEqual(t, Encrypt(4040), Encrypt(4041))
+
+	// value that is not an integer is never encrypted as 0.
+	assert.Equal(t, "", Encrypt("randomstring"))
+	assert.Equal(t, "", Encrypt(""))
+	assert.Equal(t, "", Encrypt(1.5))
 }
 
 func TestDecrypt(t *testing.T) {
+	e := Encrypt(4040)
 	var tests = []struct {
 		param    interface{}
 		expected int64
 		valid    bool
 	}{
-		{"264765440", 4040, true},
-		{"65536", 1, true},
+		{e, 4040, true},
+		{Encrypt(-1), -1, true},
+		{"65536", 0, false},
 		{"randomstring", 0, false},
+		{e[:21] + string(e[21]^1), 0, false},
+		{"-" + e[1:], 0, false},
 	}
 
 	for _, test := range tests {
@@ -53,6 +69,21 @@ func TestDecrypt(t *testing.T) {
 	}
 }
 
+func TestSetIDKey(t *testing.T) {
+	SetIDKey("first")
+	e := EncodeID(99)
+
+	SetIDKey("second")
+	_, err := DecodeID(e)
+	assert.Error(t, err)
+	assert.NotEqual(t, e, EncodeID(99))
+
+	SetIDKey("first")
+	v, err := DecodeID(e)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(99), v)
+}
+
 func TestRandomStr(t *testing.T) {
 	t.Parallel()
 
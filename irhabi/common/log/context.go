@@ -0,0 +1,34 @@
+// Copyright 2016 PT. Qasico Teknologi Indonesia. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"context"
+
+	"github.com/Sirupsen/logrus"
+)
+
+// contextKey key of the log entry in context.Context.
+type contextKey struct{}
+
+// NewContext returns copy of the context that carrying the log entry,
+// e.g entry with request id that should be used by the request handlers:
+//
+//	ctx := log.NewContext(r.Context(), log.Log.WithField("request_id", id))
+func NewContext(ctx context.Context, e *logrus.Entry) context.Context {
+	return context.WithValue(ctx, contextKey{}, e)
+}
+
+// FromContext returns the log entry carried by the context,
+// entry of the default Log is returned if the context has no entry.
+func FromContext(ctx context.Context) *logrus.Entry {
+	if ctx != nil {
+		if e, ok := ctx.Value(contextKey{}).(*logrus.Entry); ok {
+			return e
+		}
+	}
+
+	return logrus.NewEntry(Log)
+}
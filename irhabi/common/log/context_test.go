@@ -0,0 +1,31 @@
+// Copyright 2016 PT. Qasico Teknologi Indonesia. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	var output bytes.Buffer
+	l := New()
+	l.Out = &output
+
+	ctx := NewContext(context.Background(), l.WithField("request_id", "abc"))
+	FromContext(ctx).Info("inside request")
+	assert.Contains(t, output.String(), `"request_id":"abc"`)
+
+	// nested context keeps the fields of the outer entry.
+	inner := NewContext(ctx, FromContext(ctx).WithField("user_id", 1))
+	assert.Len(t, FromContext(inner).Data, 2)
+	assert.Len(t, FromContext(ctx).Data, 1)
+
+	assert.Equal(t, Log, FromContext(context.Background()).Logger)
+	assert.Empty(t, FromContext(nil).Data)
+}
@@ -0,0 +1,77 @@
+// Copyright 2016 PT. Qasico Teknologi Indonesia. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package common
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"math/big"
+	"strings"
+)
+
+// idLength length of the obfuscated id, 22 characters of base62 is enough for 128 bits.
+const idLength = 22
+
+// idCipher block cipher of EncodeID and DecodeID, the key is random
+// until SetIDKey is called so the ids are only valid for the running process.
+var idCipher = newIDCipher(randomKey())
+
+// SetIDKey set the secret key of EncodeID and DecodeID,
+// ids encoded with other key are failed to decode.
+func SetIDKey(key string) {
+	idCipher = newIDCipher([]byte(key))
+}
+
+// EncodeID returns obfuscated value of the id, the id is encrypted together with
+// the check bytes using AES and the key of SetIDKey, then encoded as 22 characters of base62.
+// The same id and key always returns the same value.
+func EncodeID(id int64) string {
+	b := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint64(b, uint64(id))
+	idCipher.Encrypt(b, b)
+
+	s := new(big.Int).SetBytes(b).Text(62)
+	return strings.Repeat("0", idLength-len(s)) + s
+}
+
+// DecodeID returns real id of the value from EncodeID, value that modified
+// or encoded with other key returns *DecryptionError.
+func DecodeID(v string) (int64, error) {
+	n, ok := new(big.Int).SetString(v, 62)
+	if len(v) != idLength || !ok || n.Sign() < 0 || n.BitLen() > aes.BlockSize*8 {
+		return 0, &DecryptionError{Values: v}
+	}
+
+	b := make([]byte, aes.BlockSize)
+	nb := n.Bytes()
+	copy(b[len(b)-len(nb):], nb)
+	idCipher.Decrypt(b, b)
+
+	// the check bytes are zero, modified value returns random bytes.
+	for _, c := range b[8:] {
+		if c != 0 {
+			return 0, &DecryptionError{Values: v}
+		}
+	}
+
+	return int64(binary.BigEndian.Uint64(b)), nil
+}
+
+// newIDCipher returns AES-256 cipher with key derived from the secret.
+func newIDCipher(secret []byte) cipher.Block {
+	k := sha256.Sum256(secret)
+	b, _ := aes.NewCipher(k[:])
+	return b
+}
+
+// randomKey returns random secret key.
+func randomKey() []byte {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return b
+}
@@ -0,0 +1,397 @@
+// Copyright 2017 PT. Qasico Teknologi Indonesia. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alfatih/irhabi/common"
+	"github.com/alfatih/irhabi/env"
+	"github.com/alfatih/irhabi/validation"
+)
+
+// redacted value of the secret fields on Dump.
+const redacted = "******"
+
+type (
+	// Configer is subset of beego/config Configer interface, so any of beego config
+	// adapters (ini, json, yaml, xml) can be used as a layer, nested keys are joined with "::".
+	Configer interface {
+		DIY(key string) (interface{}, error)
+	}
+
+	// Loader loads application config struct from the layers, each layer overrides the former:
+	// default tag, Configs, EnvFiles, environment variables and then command line Args.
+	// Fields are configured by the tags:
+	//
+	//	config  name of the key, default is snake_case of the field name, "-" is skipped
+	//	default default value of the field
+	//	env     name of the environment variable, default is the upper case key e.g DB_HOST for db.host
+	//	flag    name of the command line flag, default is the key e.g --db.host
+	//	valid   validation rules of irhabi/validation e.g required|in:mysql,postgres
+	//	secret  value is redacted on Dump, name that contains password, secret or token is also redacted
+	Loader struct {
+		Configs   []Configer // Config files e.g config.NewConfig("yaml", "conf/app.yaml") of beego
+		EnvFiles  []string   // Dot env files that loaded through irhabi/env, missing files are skipped
+		EnvPrefix string     // Prefix of the default environment variable names e.g APP_
+		Args      []string   // Command line arguments e.g os.Args[1:]
+	}
+
+	// Error failures of the loaded config keyed by the config key.
+	Error struct {
+		Errors map[string]string
+	}
+
+	// field leaf field of the config struct.
+	field struct {
+		key    string
+		path   []string
+		env    string
+		flag   string
+		def    *string
+		valid  string
+		secret bool
+		value  reflect.Value
+	}
+)
+
+// textUnmarshaler type of encoding.TextUnmarshaler.
+var textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Load loads the config struct from the configs, .env file, environment variables
+// and command line arguments of the process, e.g:
+//
+//	type Config struct {
+//		Host string `config:"host" default:"0.0.0.0:8080"`
+//		Db   struct {
+//			Engine   string `default:"mysql" valid:"required|in:mysql,postgres"`
+//			Name     string `env:"DB_NAME" valid:"required"`
+//			Password string
+//		}
+//	}
+//
+//	yaml, _ := beegoconfig.NewConfig("yaml", "conf/app.yaml")
+//	c := new(Config)
+//	err := config.Load(c, yaml)
+func Load(v interface{}, configs ...Configer) error {
+	l := &Loader{
+		Configs:  configs,
+		EnvFiles: []string{".env"},
+		Args:     os.Args[1:],
+	}
+
+	return l.Load(v)
+}
+
+// Load loads the config struct from the layers, and then validate it.
+// All of the invalid values and validation failures are returned at once as *Error.
+func (l *Loader) Load(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("config: %T is not pointer of struct", v)
+	}
+
+	for _, f := range l.EnvFiles {
+		if err := env.Load(f); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	e := &Error{Errors: make(map[string]string)}
+	for _, f := range fields(rv.Elem(), nil, l.EnvPrefix) {
+		if err := l.load(f); err != nil {
+			e.Errors[f.key] = fmt.Sprintf("The %s value is invalid.", f.key)
+			continue
+		}
+
+		if f.valid != "" {
+			// nil output is of the tag that can not be parsed e.g unknown rule.
+			if o := validation.New().Field(f.value.Interface(), f.valid); o == nil {
+				e.Errors[f.key] = fmt.Sprintf("The %s valid tag is invalid.", f.key)
+			} else if !o.Valid {
+				e.Errors[f.key] = fmt.Sprintf(o.Message(), f.key)
+			}
+		}
+	}
+
+	if len(e.Errors) > 0 {
+		return e
+	}
+
+	return nil
+}
+
+// load set value of the field from each layer.
+func (l *Loader) load(f field) error {
+	if f.def != nil {
+		if err := set(f.value, *f.def); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range l.Configs {
+		if x, err := c.DIY(strings.Join(f.path, "::")); err == nil && x != nil {
+			if err = set(f.value, x); err != nil {
+				return err
+			}
+		}
+	}
+
+	if x := os.Getenv(f.env); x != "" {
+		if err := set(f.value, x); err != nil {
+			return err
+		}
+	}
+
+	if x, ok := lookupArg(l.Args, f.flag, f.value.Kind() == reflect.Bool); ok {
+		return set(f.value, x)
+	}
+
+	return nil
+}
+
+// Error makes it compatible with `error` interface.
+func (e *Error) Error() string {
+	keys := make([]string, 0, len(e.Errors))
+	for k := range e.Errors {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	msg := make([]string, len(keys))
+	for i, k := range keys {
+		msg[i] = e.Errors[k]
+	}
+
+	return "config: " + strings.Join(msg, " ")
+}
+
+// Dump returns the effective config as sorted `key = value` lines,
+// value of the secret fields are redacted.
+func Dump(v interface{}) string {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return ""
+	}
+
+	fs := fields(rv, nil, "")
+	sort.Slice(fs, func(i, j int) bool { return fs[i].key < fs[j].key })
+
+	var b strings.Builder
+	for _, f := range fs {
+		x := text(f.value)
+		if f.secret && x != "" {
+			x = redacted
+		}
+		fmt.Fprintf(&b, "%s = %s\n", f.key, x)
+	}
+
+	return b.String()
+}
+
+// fields returns the leaf fields of the struct, the nested structs are flatten.
+func fields(v reflect.Value, path []string, envPrefix string) (fs []field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := sf.Tag.Get("config")
+		if sf.PkgPath != "" || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = common.ToUnderscore(sf.Name)
+		}
+
+		p := append(append([]string{}, path...), name)
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct && !fv.Type().Implements(textUnmarshaler) {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			fv = fv.Elem()
+		}
+
+		if fv.Kind() == reflect.Struct && fv.Type() != reflect.TypeOf(time.Time{}) && !fv.Addr().Type().Implements(textUnmarshaler) {
+			fs = append(fs, fields(fv, p, envPrefix)...)
+			continue
+		}
+
+		f := field{
+			key:    strings.Join(p, "."),
+			path:   p,
+			env:    sf.Tag.Get("env"),
+			flag:   sf.Tag.Get("flag"),
+			valid:  sf.Tag.Get("valid"),
+			secret: sf.Tag.Get("secret") == "true" || isSecret(name),
+			value:  fv,
+		}
+
+		if d, ok := sf.Tag.Lookup("default"); ok {
+			f.def = &d
+		}
+
+		if f.env == "" {
+			f.env = envPrefix + strings.ToUpper(strings.Replace(f.key, ".", "_", -1))
+		}
+
+		if f.flag == "" {
+			f.flag = f.key
+		}
+
+		fs = append(fs, f)
+	}
+
+	return
+}
+
+// isSecret returns true when the name is of secret value,
+// e.g db_pass, jwt_private_key, api_token or database_dsn.
+func isSecret(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range []string{"pass", "secret", "token", "key", "dsn", "credential"} {
+		if strings.Contains(name, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// lookupArg returns value of the last flag with the name e.g -name value, --name=value,
+// bool flag without value is true.
+func lookupArg(args []string, name string, isBool bool) (value string, ok bool) {
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		if a == "--" {
+			break
+		}
+
+		if !strings.HasPrefix(a, "-") {
+			continue
+		}
+
+		a = strings.TrimPrefix(strings.TrimPrefix(a, "-"), "-")
+		if strings.HasPrefix(a, name+"=") {
+			value, ok = a[len(name)+1:], true
+		} else if a == name {
+			if isBool {
+				value, ok = "true", true
+			} else if i+1 < len(args) {
+				value, ok = args[i+1], true
+				i++
+			}
+		}
+	}
+
+	return
+}
+
+// set convert the value of the layer into the field.
+func set(v reflect.Value, x interface{}) (err error) {
+	if v.Addr().Type().Implements(textUnmarshaler) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(scalar(x)))
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(scalar(x))
+	case reflect.Bool:
+		var b bool
+		if b, err = strconv.ParseBool(scalar(x)); err == nil {
+			v.SetBool(b)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Type() == reflect.TypeOf(time.Duration(0)) {
+			var d time.Duration
+			if d, err = time.ParseDuration(scalar(x)); err == nil {
+				v.SetInt(int64(d))
+			}
+			return
+		}
+
+		var i int64
+		if i, err = strconv.ParseInt(scalar(x), 10, v.Type().Bits()); err == nil {
+			v.SetInt(i)
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var i uint64
+		if i, err = strconv.ParseUint(scalar(x), 10, v.Type().Bits()); err == nil {
+			v.SetUint(i)
+		}
+	case reflect.Float32, reflect.Float64:
+		var f float64
+		if f, err = strconv.ParseFloat(scalar(x), v.Type().Bits()); err == nil {
+			v.SetFloat(f)
+		}
+	case reflect.Slice:
+		var items []string
+		if l, ok := x.([]interface{}); ok {
+			for _, i := range l {
+				items = append(items, scalar(i))
+			}
+		} else if l, ok := x.([]string); ok {
+			items = l
+		} else {
+			for _, i := range strings.Split(scalar(x), ",") {
+				if i = strings.TrimSpace(i); i != "" {
+					items = append(items, i)
+				}
+			}
+		}
+
+		s := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err = set(s.Index(i), item); err != nil {
+				return
+			}
+		}
+		v.Set(s)
+	default:
+		err = fmt.Errorf("config: unsupported type %s", v.Type())
+	}
+
+	return
+}
+
+// scalar returns text of the value from the layer,
+// float of json number is written without exponent.
+func scalar(x interface{}) string {
+	switch s := x.(type) {
+	case string:
+		return s
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	case nil:
+		return ""
+	}
+
+	return fmt.Sprint(x)
+}
+
+// text returns text of the field value.
+func text(v reflect.Value) string {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		b, _ := m.MarshalText()
+		return string(b)
+	}
+
+	if v.Kind() == reflect.Slice {
+		l := make([]string, v.Len())
+		for i := range l {
+			l[i] = text(v.Index(i))
+		}
+		return strings.Join(l, ",")
+	}
+
+	return fmt.Sprint(v.Interface())
+}
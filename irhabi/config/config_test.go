@@ -0,0 +1,193 @@
+// Copyright 2017 PT. Qasico Teknologi Indonesia. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package config
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testDatabase struct {
+	Engine   string `default:"mysql" valid:"required|in:mysql,postgres"`
+	Host     string `default:"0.0.0.0"`
+	Port     int    `default:"3306"`
+	Name     string `valid:"required"`
+	Password string
+}
+
+type testConfig struct {
+	Debug   bool          `flag:"debug"`
+	Host    string        `config:"host" default:"0.0.0.0:8080"`
+	Timeout time.Duration `default:"30s"`
+	Origins []string      `default:"*"`
+	APIKey  string        `config:"api_key" env:"TEST_API_KEY" secret:"true"`
+	Db      testDatabase  `config:"db"`
+	Mailer  *struct {
+		Port uint `default:"587"`
+	}
+	internal string
+	Ignored  string `config:"-"`
+}
+
+// fakeConfig beego Configer of map with nested keys.
+type fakeConfig map[string]interface{}
+
+func (c fakeConfig) DIY(key string) (interface{}, error) {
+	if v, ok := c[key]; ok {
+		return v, nil
+	}
+
+	return nil, errors.New("not exist key")
+}
+
+func clearEnv() {
+	for _, k := range []string{"DEBUG", "HOST", "TIMEOUT", "TEST_API_KEY", "DB_ENGINE", "DB_HOST", "DB_PORT", "DB_NAME", "DB_PASSWORD", "APP_DB_NAME"} {
+		os.Unsetenv(k)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	os.Setenv("DB_HOST", "10.0.0.1")
+	os.Setenv("DB_PORT", "3308")
+	os.Setenv("TEST_API_KEY", "s3cr3t")
+
+	c := new(testConfig)
+	l := &Loader{
+		Configs: []Configer{
+			fakeConfig{"host": "0.0.0.0:9000", "db::host": "db.local", "db::port": float64(3309), "origins": []interface{}{"a.com", "b.com"}},
+			fakeConfig{"db::password": "p4ss", "timeout": "1m"},
+		},
+		EnvFiles: []string{"_fixture/app.env", "_fixture/missing.env"},
+		Args:     []string{"serve", "--debug", "-db.engine", "postgres", "--host=0.0.0.0:9090"},
+	}
+
+	if !assert.NoError(t, l.Load(c)) {
+		return
+	}
+
+	assert.True(t, c.Debug)
+	assert.Equal(t, "0.0.0.0:9090", c.Host)
+	assert.Equal(t, time.Minute, c.Timeout)
+	assert.Equal(t, []string{"a.com", "b.com"}, c.Origins)
+	assert.Equal(t, "s3cr3t", c.APIKey)
+	assert.Equal(t, "postgres", c.Db.Engine)
+	assert.Equal(t, "10.0.0.1", c.Db.Host)
+	assert.Equal(t, 3308, c.Db.Port)
+	assert.Equal(t, "konektifa_app", c.Db.Name)
+	assert.Equal(t, "p4ss", c.Db.Password)
+	assert.Equal(t, uint(587), c.Mailer.Port)
+
+	// .env never override the environment variable.
+	assert.Equal(t, "3308", os.Getenv("DB_PORT"))
+}
+
+func TestLoadErrors(t *testing.T) {
+	clearEnv()
+	defer clearEnv()
+
+	os.Setenv("TIMEOUT", "30")
+	c := new(testConfig)
+	l := &Loader{
+		Configs: []Configer{fakeConfig{"db::port": "localhost"}},
+		Args:    []string{"--db.engine=oracle"},
+	}
+
+	err := l.Load(c)
+	if assert.IsType(t, &Error{}, err) {
+		assert.Equal(t, map[string]string{
+			"timeout":   "The timeout value is invalid.",
+			"db.port":   "The db.port value is invalid.",
+			"db.engine": "The selected db.engine is invalid.",
+			"db.name":   "The db.name field is required.",
+		}, err.(*Error).Errors)
+		assert.True(t, strings.HasPrefix(err.Error(), "config: The selected db.engine is invalid. The db.name"))
+	}
+
+	assert.Error(t, l.Load(*c))
+
+	l = &Loader{EnvPrefix: "APP_"}
+	os.Setenv("APP_DB_NAME", "app")
+	c = new(testConfig)
+	assert.NoError(t, l.Load(c))
+	assert.Equal(t, "app", c.Db.Name)
+
+	// tag that can not be parsed is not skipping the validation.
+	var bad struct {
+		Name string `default:"app" valid:"required|unknown"`
+	}
+	err = new(Loader).Load(&bad)
+	if assert.IsType(t, &Error{}, err) {
+		assert.Equal(t, map[string]string{"name": "The name valid tag is invalid."}, err.(*Error).Errors)
+	}
+}
+
+func TestDump(t *testing.T) {
+	c := new(testConfig)
+	c.APIKey = "s3cr3t"
+	c.Db.Name = "app"
+	c.Db.Password = "p4ss"
+	c.Origins = []string{"a.com", "b.com"}
+	c.Timeout = time.Minute
+
+	d := Dump(c)
+	assert.Contains(t, d, "api_key = ******\n")
+	assert.Contains(t, d, "db.password = ******\n")
+	assert.Contains(t, d, "db.name = app\n")
+	assert.Contains(t, d, "origins = a.com,b.com\n")
+	assert.Contains(t, d, "timeout = 1m0s\n")
+	assert.NotContains(t, d, "s3cr3t")
+	assert.NotContains(t, d, "internal")
+	assert.NotContains(t, d, "ignored")
+	assert.True(t, strings.HasPrefix(d, "api_key = "))
+}
+
+func TestDumpSecretNames(t *testing.T) {
+	c := struct {
+		JwtPrivateKey  string `config:"jwt_private_key"`
+		DbPass         string `config:"db_pass"`
+		APIKey         string `config:"api_key"`
+		ReportDSN      string `config:"report_dsn"`
+		AwsCredentials string `config:"aws_credentials"`
+		Host           string `config:"host"`
+	}{"k", "p", "a", "d", "c", "0.0.0.0"}
+
+	d := Dump(&c)
+	for _, name := range []string{"jwt_private_key", "db_pass", "api_key", "report_dsn", "aws_credentials"} {
+		assert.Contains(t, d, name+" = ******\n")
+	}
+	assert.Contains(t, d, "host = 0.0.0.0\n")
+}
+
+func TestLookupArg(t *testing.T) {
+	var cases = []struct {
+		args   []string
+		name   string
+		isBool bool
+		value  string
+		ok     bool
+	}{
+		{[]string{"-host", "a"}, "host", false, "a", true},
+		{[]string{"--host=a", "--host=b"}, "host", false, "b", true},
+		{[]string{"--hostname=a"}, "host", false, "", false},
+		{[]string{"--host"}, "host", false, "", false},
+		{[]string{"--debug", "run"}, "debug", true, "true", true},
+		{[]string{"--debug=false"}, "debug", true, "false", true},
+		{[]string{"--", "--debug"}, "debug", true, "", false},
+	}
+
+	for _, c := range cases {
+		v, ok := lookupArg(c.args, c.name, c.isBool)
+		assert.Equal(t, c.value, v, strings.Join(c.args, " "))
+		assert.Equal(t, c.ok, ok, strings.Join(c.args, " "))
+	}
+}
@@ -0,0 +1,260 @@
+package irhabi
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/labstack/echo"
+)
+
+// ErrForbidden error when user has no grants to access the route.
+var ErrForbidden = echo.NewHTTPError(http.StatusForbidden)
+
+// Grantee is user model that can be authorized,
+// should be implemented by the user model returned by jwtUser.
+type Grantee interface {
+	Roles() []string
+	Permissions() []string
+}
+
+// Authorizer declarative authorization of echo routes and groups,
+// user is resolved from jwt token using Context.JwtUsers.
+type Authorizer struct {
+	Users  jwtUser // Model to get user from jwt token
+	Policy *Policy // Policy used by Enforce, can be nil
+}
+
+// NewAuthorizer creates authorizer with user model and optional policy.
+func NewAuthorizer(users jwtUser, policy ...*Policy) *Authorizer {
+	a := &Authorizer{Users: users}
+	if len(policy) > 0 {
+		a.Policy = policy[0]
+	}
+
+	return a
+}
+
+// Roles returns middleware that allow the request only
+// if the user has at least one of the roles.
+func (a *Authorizer) Roles(roles ...string) echo.MiddlewareFunc {
+	return a.middleware(func(g Grantee, _ echo.Context) bool {
+		return containsAny(g.Roles(), roles)
+	})
+}
+
+// Permissions returns middleware that allow the request only
+// if the user has all of the permissions.
+func (a *Authorizer) Permissions(perms ...string) echo.MiddlewareFunc {
+	return a.middleware(func(g Grantee, _ echo.Context) bool {
+		return containsAll(g.Permissions(), perms)
+	})
+}
+
+// Enforce returns middleware that allow the request only if
+// one of the user roles is allowed by policy to access the request path and method.
+func (a *Authorizer) Enforce() echo.MiddlewareFunc {
+	return a.middleware(func(g Grantee, c echo.Context) bool {
+		if a.Policy == nil {
+			return false
+		}
+
+		req := c.Request()
+		for _, r := range g.Roles() {
+			if a.Policy.Enforce(r, req.URL.Path, req.Method) {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+// middleware resolve the user and check the grants,
+// will return error 401 if user is not found and 403 if the check fails.
+func (a *Authorizer) middleware(check func(Grantee, echo.Context) bool) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			g, ok := a.grantee(c)
+			if !ok {
+				return echo.ErrUnauthorized
+			}
+
+			if !check(g, c) {
+				return ErrForbidden
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// grantee returns user from request context, the user is stored
+// so it will not loaded again by other authorization middleware.
+func (a *Authorizer) grantee(c echo.Context) (Grantee, bool) {
+	if g, ok := c.Get("grantee").(Grantee); ok {
+		return g, true
+	}
+
+	ctx, ok := c.(*Context)
+	if !ok {
+		ctx = NewContext(c)
+	}
+
+	g, ok := ctx.JwtUsers(a.Users).(Grantee)
+	if ok {
+		c.Set("grantee", g)
+	}
+
+	return g, ok
+}
+
+// Policy access control list using casbin policy format,
+// rule `p, role, /path/*, GET` allowing role to access the path with method,
+// and `g, role, parent` make the role inheriting parent rules.
+type Policy struct {
+	mu    sync.RWMutex
+	rules []policyRule
+	roles map[string][]string
+}
+
+// policyRule single policy rule.
+type policyRule struct {
+	sub string
+	obj string
+	act string
+}
+
+// NewPolicy creates an empty policy.
+func NewPolicy() *Policy {
+	return &Policy{roles: make(map[string][]string)}
+}
+
+// LoadPolicy creates policy from casbin policy csv file.
+func LoadPolicy(file string) (*Policy, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParsePolicy(f)
+}
+
+// ParsePolicy creates policy by reading casbin policy csv lines.
+func ParsePolicy(r io.Reader) (*Policy, error) {
+	p := NewPolicy()
+	s := bufio.NewScanner(r)
+
+	var n int
+	for s.Scan() {
+		n++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		t := strings.Split(line, ",")
+		for i := range t {
+			t[i] = strings.TrimSpace(t[i])
+		}
+
+		switch {
+		case t[0] == "p" && len(t) == 4:
+			p.AddPolicy(t[1], t[2], t[3])
+		case t[0] == "g" && len(t) == 3:
+			p.AddRole(t[1], t[2])
+		default:
+			return nil, fmt.Errorf("policy: invalid rule at line %d: %s", n, line)
+		}
+	}
+
+	return p, s.Err()
+}
+
+// AddPolicy allowing subject to do action into the object,
+// object can be ended with `*` to match any path with the prefix
+// and action `*` will match any method.
+func (p *Policy) AddPolicy(sub string, obj string, act string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.rules = append(p.rules, policyRule{sub, obj, act})
+}
+
+// AddRole make the subject inheriting rules of the role.
+func (p *Policy) AddRole(sub string, role string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.roles[sub] = append(p.roles[sub], role)
+}
+
+// Enforce check is the subject or roles it inherits
+// is allowed to do action into object.
+func (p *Policy) Enforce(sub string, obj string, act string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	subs := p.inherits(sub, map[string]bool{})
+	for _, r := range p.rules {
+		if subs[r.sub] && keyMatch(obj, r.obj) && (r.act == act || r.act == "*") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inherits returns the subject with all roles it inherits.
+func (p *Policy) inherits(sub string, visited map[string]bool) map[string]bool {
+	if visited[sub] {
+		return visited
+	}
+
+	visited[sub] = true
+	for _, r := range p.roles[sub] {
+		p.inherits(r, visited)
+	}
+
+	return visited
+}
+
+// keyMatch check is the key is matching the pattern,
+// pattern can contains `*` that matching any suffix.
+func keyMatch(key string, pattern string) bool {
+	i := strings.Index(pattern, "*")
+	if i == -1 {
+		return key == pattern
+	}
+
+	return strings.HasPrefix(key, pattern[:i])
+}
+
+// containsAny returns true if one of the values exists in the list.
+func containsAny(list []string, values []string) bool {
+	for _, v := range values {
+		for _, l := range list {
+			if l == v {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// containsAll returns true if all of the values exists in the list.
+func containsAll(list []string, values []string) bool {
+	for _, v := range values {
+		if !containsAny(list, []string{v}) {
+			return false
+		}
+	}
+
+	return true
+}
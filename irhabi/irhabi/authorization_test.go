@@ -0,0 +1,118 @@
+package irhabi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+type grantedUser struct {
+	ID    int64
+	roles []string
+	perms []string
+}
+
+func (u *grantedUser) Roles() []string {
+	return u.roles
+}
+
+func (u *grantedUser) Permissions() []string {
+	return u.perms
+}
+
+type testGrantedUsers map[int64]*grantedUser
+
+func (t testGrantedUsers) GetUser(id int64) (interface{}, error) {
+	if u, ok := t[id]; ok {
+		return u, nil
+	}
+
+	return nil, ErrDataNotExists("id", "user not exists")
+}
+
+const testPolicy = `
+p, staff, /dataset1/*, GET
+p, staff, /dataset1/resource1, POST
+p, admin, /dataset1/*, *
+g, superadmin, admin
+`
+
+func TestPolicy(t *testing.T) {
+	p, err := ParsePolicy(strings.NewReader(testPolicy))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var cases = []struct {
+		sub      string
+		obj      string
+		act      string
+		expected bool
+	}{
+		{"staff", "/dataset1/resource2", "GET", true},
+		{"staff", "/dataset1/resource1", "POST", true},
+		{"staff", "/dataset1/resource2", "POST", false},
+		{"staff", "/dataset2/resource1", "GET", false},
+		{"admin", "/dataset1/resource2", "DELETE", true},
+		{"superadmin", "/dataset1/resource2", "DELETE", true},
+		{"guest", "/dataset1/resource2", "GET", false},
+	}
+
+	for _, c := range cases {
+		assert.Equal(t, c.expected, p.Enforce(c.sub, c.obj, c.act), c.sub+" "+c.act+" "+c.obj)
+	}
+
+	_, err = ParsePolicy(strings.NewReader("x, staff"))
+	assert.Error(t, err)
+}
+
+func TestAuthorizer(t *testing.T) {
+	p, _ := ParsePolicy(strings.NewReader(testPolicy))
+	a := NewAuthorizer(testGrantedUsers{
+		1: {ID: 1, roles: []string{"staff"}, perms: []string{"user.read"}},
+		2: {ID: 2, roles: []string{"superadmin"}, perms: []string{"user.read", "user.write"}},
+	}, p)
+
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "OK")
+	}
+
+	e := New()
+	g := e.Group("/dataset1", Authorized(), a.Enforce())
+	g.GET("/resource2", handler)
+	g.DELETE("/resource2", handler)
+	e.GET("/admin", handler, Authorized(), a.Roles("admin", "superadmin"))
+	e.POST("/users", handler, Authorized(), a.Permissions("user.read", "user.write"))
+
+	var cases = []struct {
+		user     int64
+		method   string
+		path     string
+		expected int
+	}{
+		{1, echo.GET, "/dataset1/resource2", http.StatusOK},
+		{1, echo.DELETE, "/dataset1/resource2", http.StatusForbidden},
+		{2, echo.DELETE, "/dataset1/resource2", http.StatusOK},
+		{1, echo.GET, "/admin", http.StatusForbidden},
+		{2, echo.GET, "/admin", http.StatusOK},
+		{1, echo.POST, "/users", http.StatusForbidden},
+		{2, echo.POST, "/users", http.StatusOK},
+		{3, echo.GET, "/admin", http.StatusUnauthorized},
+	}
+
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, c.path, nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+JwtToken("id", c.user))
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, c.expected, rec.Code, c.method+" "+c.path)
+		if c.expected == http.StatusForbidden {
+			assert.JSONEq(t, `{"status":"fail","message":"Forbidden"}`, rec.Body.String())
+		}
+	}
+}
@@ -1,10 +1,16 @@
 package irhabi
 
 import (
+	"encoding"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"reflect"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -12,6 +18,10 @@ import (
 	"github.com/labstack/echo"
 )
 
+// defaultMaxMemory maximum bytes of multipart form that stored in memory,
+// the rest of the files will be stored on disk temporary files.
+const defaultMaxMemory = 32 << 20
+
 type (
 	// Custom echo binder
 	binder struct{}
@@ -52,14 +62,55 @@ func (v *binderValidator) lazyinit() {
 	})
 }
 
-// Bind is decode request body and injecting into interfaces,
-// We only accept json data type other type will return error bad requests.
+// Bind is decode request into interfaces, path and query parameters
+// are injected through `param` and `query` struct tags, then the request body
+// is decoded by the content type, we accept json, xml, form and multipart form
+// other type will return error unsupported media type.
 // Also automaticly validate data with interfaces.
 func (b binder) Bind(i interface{}, ctx echo.Context) (err error) {
 	bindValidator.lazyinit()
 	req := ctx.Request()
+
+	if err = b.bindParams(i, ctx); err != nil {
+		return err
+	}
+
+	if req.ContentLength != 0 {
+		if err = b.bindBody(i, req); err != nil {
+			return err
+		}
+	}
+
+	return bindValidator.validate(i)
+}
+
+// bindParams injecting path parameters and query string into interfaces.
+func (b binder) bindParams(i interface{}, ctx echo.Context) (err error) {
+	names := ctx.ParamNames()
+	values := ctx.ParamValues()
+	params := make(map[string][]string, len(names))
+	for x, n := range names {
+		if x < len(values) {
+			params[n] = []string{values[x]}
+		}
+	}
+
+	if err = bindData(i, params, nil, "param"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	if err = bindData(i, ctx.QueryParams(), nil, "query"); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+	}
+
+	return nil
+}
+
+// bindBody decode request body based on the content type.
+func (b binder) bindBody(i interface{}, req *http.Request) (err error) {
 	ctype := req.Header.Get(echo.HeaderContentType)
-	if strings.HasPrefix(ctype, echo.MIMEApplicationJSON) {
+	switch {
+	case strings.HasPrefix(ctype, echo.MIMEApplicationJSON):
 		if err = json.NewDecoder(req.Body).Decode(i); err != nil && err != io.EOF {
 			if ute, ok := err.(*json.UnmarshalTypeError); ok {
 				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unmarshal type error: expected=%v, got=%v, offset=%v", ute.Type, ute.Value, ute.Offset))
@@ -69,7 +120,210 @@ func (b binder) Bind(i interface{}, ctx echo.Context) (err error) {
 				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
 			}
 		}
-		return bindValidator.validate(i)
+	case strings.HasPrefix(ctype, echo.MIMEApplicationXML), strings.HasPrefix(ctype, echo.MIMETextXML):
+		if err = xml.NewDecoder(req.Body).Decode(i); err != nil && err != io.EOF {
+			if ute, ok := err.(*xml.UnsupportedTypeError); ok {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("unsupported type error: type=%v, error=%v", ute.Type, ute.Error()))
+			} else if se, ok := err.(*xml.SyntaxError); ok {
+				return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("syntax error: line=%v, error=%v", se.Line, se.Error()))
+			} else {
+				return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+			}
+		}
+	case strings.HasPrefix(ctype, echo.MIMEApplicationForm):
+		if err = req.ParseForm(); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		if err = bindData(i, req.PostForm, nil, "form"); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	case strings.HasPrefix(ctype, echo.MIMEMultipartForm):
+		if err = req.ParseMultipartForm(defaultMaxMemory); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+
+		if err = bindData(i, req.MultipartForm.Value, req.MultipartForm.File, "form"); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err.Error())
+		}
+	default:
+		return echo.ErrUnsupportedMediaType
+	}
+
+	return nil
+}
+
+var (
+	fileHeaderType  = reflect.TypeOf((*multipart.FileHeader)(nil))
+	fileHeadersType = reflect.TypeOf([]*multipart.FileHeader(nil))
+	unmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// bindData injecting values and files into struct fields by the tag name,
+// form tag will fallback into json tag and then the field name,
+// other tag only bind the field that explicitly tagged.
+func bindData(ptr interface{}, data map[string][]string, files map[string][]*multipart.FileHeader, tag string) error {
+	if len(data) == 0 && len(files) == 0 {
+		return nil
 	}
-	return echo.ErrUnsupportedMediaType
+
+	val := reflect.ValueOf(ptr)
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return nil
+	}
+
+	val = val.Elem()
+	if val.Kind() != reflect.Struct {
+		return nil
+	}
+
+	typ := val.Type()
+	for x := 0; x < typ.NumField(); x++ {
+		tf := typ.Field(x)
+		sf := val.Field(x)
+		if !sf.CanSet() {
+			continue
+		}
+
+		name := fieldName(tf, tag)
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			if sf.Kind() == reflect.Struct && !reflect.PtrTo(sf.Type()).Implements(unmarshalerType) {
+				if err := bindData(sf.Addr().Interface(), data, files, tag); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		switch sf.Type() {
+		case fileHeaderType:
+			if fh := files[name]; len(fh) > 0 {
+				sf.Set(reflect.ValueOf(fh[0]))
+			}
+			continue
+		case fileHeadersType:
+			if fh := files[name]; len(fh) > 0 {
+				sf.Set(reflect.ValueOf(fh))
+			}
+			continue
+		}
+
+		values, ok := data[name]
+		if !ok || len(values) == 0 {
+			continue
+		}
+
+		if sf.Kind() == reflect.Slice && sf.Type().Elem().Kind() != reflect.Uint8 {
+			slice := reflect.MakeSlice(sf.Type(), len(values), len(values))
+			for y, v := range values {
+				if err := setField(slice.Index(y), v); err != nil {
+					return fmt.Errorf("invalid value of %s: %s", name, err.Error())
+				}
+			}
+			sf.Set(slice)
+			continue
+		}
+
+		if err := setField(sf, values[0]); err != nil {
+			return fmt.Errorf("invalid value of %s: %s", name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// fieldName returns the key name of struct field for the tag.
+func fieldName(f reflect.StructField, tag string) string {
+	if n := tagName(f.Tag.Get(tag)); n != "" {
+		return n
+	}
+
+	if tag != "form" {
+		return ""
+	}
+
+	if n := tagName(f.Tag.Get("json")); n != "" {
+		return n
+	}
+
+	if f.Anonymous {
+		return ""
+	}
+
+	return f.Name
+}
+
+// tagName strip options from the tag values.
+func tagName(t string) string {
+	if i := strings.Index(t, ","); i >= 0 {
+		return t[:i]
+	}
+
+	return t
+}
+
+// setField convert string value into type of the field.
+func setField(f reflect.Value, v string) (err error) {
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			f.Set(reflect.New(f.Type().Elem()))
+		}
+		return setField(f.Elem(), v)
+	}
+
+	if f.CanAddr() {
+		if u, ok := f.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(v))
+		}
+	}
+
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(v)
+	case reflect.Bool:
+		var b bool
+		if v != "" {
+			if b, err = strconv.ParseBool(v); err != nil {
+				return err
+			}
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var n int64
+		if v != "" {
+			if n, err = strconv.ParseInt(v, 10, f.Type().Bits()); err != nil {
+				return err
+			}
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		var n uint64
+		if v != "" {
+			if n, err = strconv.ParseUint(v, 10, f.Type().Bits()); err != nil {
+				return err
+			}
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		var n float64
+		if v != "" {
+			if n, err = strconv.ParseFloat(v, f.Type().Bits()); err != nil {
+				return err
+			}
+		}
+		f.SetFloat(n)
+	case reflect.Slice:
+		if f.Type().Elem().Kind() != reflect.Uint8 {
+			return errors.New("unsupported type")
+		}
+		f.SetBytes([]byte(v))
+	default:
+		return errors.New("unsupported type")
+	}
+
+	return nil
 }
@@ -2,6 +2,7 @@ package irhabi
 
 import (
 	"bytes"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -84,6 +85,112 @@ func TestEmptyBodyShouldNotError(t *testing.T) {
 	assert.IsType(t, &validation.Output{}, e)
 }
 
+type FormStruct struct {
+	ID       int64                   `param:"id"`
+	Page     int                     `query:"page"`
+	Tags     []string                `query:"tags"`
+	Name     string                  `json:"name" xml:"name" valid:"required"`
+	Age      *int                    `form:"age" xml:"age"`
+	Active   bool                    `form:"active" xml:"active"`
+	Avatar   *multipart.FileHeader   `form:"avatar" xml:"-"`
+	Gallery  []*multipart.FileHeader `form:"gallery" xml:"-"`
+	Internal string                  `form:"-" xml:"-"`
+}
+
+func TestBinderForm(t *testing.T) {
+	var obj FormStruct
+	ctx := requestWithBody("POST", "/", `name=Jon+Snow&age=20&active=true&Internal=x`, echo.MIMEApplicationForm)
+	if assert.NoError(t, ctx.Bind(&obj)) {
+		assert.Equal(t, "Jon Snow", obj.Name)
+		assert.Equal(t, 20, *obj.Age)
+		assert.True(t, obj.Active)
+		assert.Empty(t, obj.Internal)
+	}
+
+	obj = FormStruct{}
+	ctx = requestWithBody("POST", "/", `age=20`, echo.MIMEApplicationForm)
+	e := ctx.Bind(&obj)
+	if assert.IsType(t, &validation.Output{}, e) {
+		assert.Equal(t, map[string]string{"name": "The name field is required."}, e.(*validation.Output).Messages())
+	}
+
+	obj = FormStruct{}
+	ctx = requestWithBody("POST", "/", `name=Jon&age=twenty`, echo.MIMEApplicationForm)
+	assert.IsType(t, &echo.HTTPError{}, ctx.Bind(&obj))
+}
+
+func TestBinderMultipart(t *testing.T) {
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	mw.WriteField("name", "Jon Snow")
+	mw.WriteField("age", "20")
+	fw, _ := mw.CreateFormFile("avatar", "avatar.png")
+	fw.Write([]byte("avatar"))
+	fw, _ = mw.CreateFormFile("gallery", "1.png")
+	fw.Write([]byte("1"))
+	fw, _ = mw.CreateFormFile("gallery", "2.png")
+	fw.Write([]byte("2"))
+	mw.Close()
+
+	var obj FormStruct
+	ctx := requestWithBody("POST", "/", body.String(), mw.FormDataContentType())
+	if assert.NoError(t, ctx.Bind(&obj)) {
+		assert.Equal(t, "Jon Snow", obj.Name)
+		assert.Equal(t, 20, *obj.Age)
+		if assert.NotNil(t, obj.Avatar) {
+			assert.Equal(t, "avatar.png", obj.Avatar.Filename)
+		}
+		assert.Len(t, obj.Gallery, 2)
+	}
+}
+
+func TestBinderXML(t *testing.T) {
+	var obj FormStruct
+	ctx := requestWithBody("POST", "/", `<FormStruct><name>Jon Snow</name><age>20</age></FormStruct>`, echo.MIMEApplicationXML)
+	if assert.NoError(t, ctx.Bind(&obj)) {
+		assert.Equal(t, "Jon Snow", obj.Name)
+		assert.Equal(t, 20, *obj.Age)
+	}
+
+	obj = FormStruct{}
+	ctx = requestWithBody("POST", "/", `<FormStruct><age>20</age></FormStruct>`, echo.MIMETextXML)
+	assert.IsType(t, &validation.Output{}, ctx.Bind(&obj))
+
+	obj = FormStruct{}
+	ctx = requestWithBody("POST", "/", `<FormStruct><name>Jon`, echo.MIMEApplicationXML)
+	assert.IsType(t, &echo.HTTPError{}, ctx.Bind(&obj))
+}
+
+func TestBinderParams(t *testing.T) {
+	var obj FormStruct
+	ctx := requestWithBody("GET", "/users/10?page=2&tags=a&tags=b&name=Jon", "", "")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("10")
+
+	e := ctx.Bind(&obj)
+	assert.IsType(t, &validation.Output{}, e)
+	assert.Equal(t, int64(10), obj.ID)
+	assert.Equal(t, 2, obj.Page)
+	assert.Equal(t, []string{"a", "b"}, obj.Tags)
+	assert.Empty(t, obj.Name)
+
+	obj = FormStruct{}
+	ctx = requestWithBody("POST", "/users/10?page=3", `{"name": "Jon Snow"}`, "application/json")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("10")
+	if assert.NoError(t, ctx.Bind(&obj)) {
+		assert.Equal(t, int64(10), obj.ID)
+		assert.Equal(t, 3, obj.Page)
+		assert.Equal(t, "Jon Snow", obj.Name)
+	}
+
+	obj = FormStruct{}
+	ctx = requestWithBody("GET", "/users/x", "", "")
+	ctx.SetParamNames("id")
+	ctx.SetParamValues("x")
+	assert.IsType(t, &echo.HTTPError{}, ctx.Bind(&obj))
+}
+
 func requestWithBody(method, path, body string, ctype string) echo.Context {
 	e := echo.New()
 	e.Binder = binder{}
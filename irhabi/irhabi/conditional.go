@@ -0,0 +1,159 @@
+package irhabi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// Generation of ETag on GET and HEAD responses, see Config.ETag.
+const (
+	ETagStrong = "strong"
+	ETagWeak   = "weak"
+)
+
+// Headers of the conditional requests.
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+// ErrPreconditionFailed error of If-Match header that not matched
+// the current ETag of the data, served with status 412.
+var ErrPreconditionFailed = echo.NewHTTPError(http.StatusPreconditionFailed)
+
+// SetETag set ETag header of the response, the tag is quoted when not quoted yet.
+// ETag that set before Serve is used instead of the generated one.
+func (c *Context) SetETag(tag string, weak bool) {
+	if strings.HasPrefix(tag, "W/") {
+		tag = tag[2:]
+		weak = true
+	}
+
+	if !strings.HasPrefix(tag, `"`) {
+		tag = `"` + tag + `"`
+	}
+
+	if weak {
+		tag = "W/" + tag
+	}
+
+	c.Response().Header().Set(headerETag, tag)
+}
+
+// SetLastModified set Last-Modified header of the response,
+// GET and HEAD with If-Modified-Since that not older are served as 304.
+func (c *Context) SetLastModified(t time.Time) {
+	c.Response().Header().Set(echo.HeaderLastModified, t.UTC().Format(http.TimeFormat))
+}
+
+// CacheControl set Cache-Control header of the response e.g:
+//
+//	ctx.CacheControl("private", "max-age=60")
+func (c *Context) CacheControl(directives ...string) {
+	c.Response().Header().Set("Cache-Control", strings.Join(directives, ", "))
+}
+
+// IfMatch check If-Match header of the request with the current ETag of the data,
+// it returns ErrPreconditionFailed when not matched, request without If-Match is passed.
+// it should be checked before the data is updated or deleted, e.g:
+//
+//	if e = ctx.IfMatch(cuxs.ModelETag(u)); e != nil {
+//		return ctx.Serve(e)
+//	}
+func (c *Context) IfMatch(etag string) error {
+	h := c.Request().Header.Get(headerIfMatch)
+	if h == "" || etagMatch(h, etag, false) {
+		return nil
+	}
+
+	return ErrPreconditionFailed
+}
+
+// ModelETag returns strong ETag of the model, generated from the Version field
+// or the UpdatedAt field of the model, otherwise from the json of the model.
+func ModelETag(m interface{}) string {
+	var src []byte
+	v := reflect.Indirect(reflect.ValueOf(m))
+	if v.Kind() == reflect.Struct {
+		if f := v.FieldByName("Version"); f.IsValid() && f.Kind() >= reflect.Int && f.Kind() <= reflect.Uint64 {
+			src = []byte(fmt.Sprintf("%s:version:%v", v.Type(), f.Interface()))
+		} else if f := v.FieldByName("UpdatedAt"); f.IsValid() && f.Type() == reflect.TypeOf(time.Time{}) {
+			t := f.Interface().(time.Time)
+			src = []byte(fmt.Sprintf("%s:updated_at:%d", v.Type(), t.UnixNano()))
+		}
+	}
+
+	if src == nil {
+		src, _ = json.Marshal(m)
+	}
+
+	return etagOf(src, false)
+}
+
+// notModified set the generated ETag when enabled, and returns true
+// when the request conditions are matched with the response.
+func (c *Context) notModified() bool {
+	h := c.Response().Header()
+	if h.Get(headerETag) == "" && (Config.ETag == ETagStrong || Config.ETag == ETagWeak) {
+		if b, err := json.Marshal(c.responseFormat); err == nil {
+			h.Set(headerETag, etagOf(b, Config.ETag == ETagWeak))
+		}
+	}
+
+	if inm := c.Request().Header.Get(headerIfNoneMatch); inm != "" {
+		return h.Get(headerETag) != "" && etagMatch(inm, h.Get(headerETag), true)
+	}
+
+	ims, err := http.ParseTime(c.Request().Header.Get(echo.HeaderIfModifiedSince))
+	if err != nil {
+		return false
+	}
+
+	lm, err := http.ParseTime(h.Get(echo.HeaderLastModified))
+	return err == nil && !lm.After(ims)
+}
+
+// etagOf returns quoted hash of the content.
+func etagOf(b []byte, weak bool) string {
+	sum := sha256.Sum256(b)
+	tag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	if weak {
+		return "W/" + tag
+	}
+
+	return tag
+}
+
+// etagMatch returns true when one of the tags in the header is matched with the etag,
+// weak comparison ignores the W/ prefix, while strong comparison never matches weak tags.
+func etagMatch(header string, etag string, weak bool) bool {
+	if etag == "" {
+		return false
+	}
+
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if t == "*" {
+			return true
+		}
+
+		if weak {
+			if strings.TrimPrefix(t, "W/") == strings.TrimPrefix(etag, "W/") {
+				return true
+			}
+		} else if t == etag && !strings.HasPrefix(t, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
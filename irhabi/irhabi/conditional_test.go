@@ -0,0 +1,176 @@
+package irhabi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alfatih/irhabi/orm"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextServeETag(t *testing.T) {
+	defer func(v string) { Config.ETag = v }(Config.ETag)
+
+	serve := func(method string, header string, value string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		ctx, _ := fakeContext(method, "/", "", rec)
+		ctx.Request().Header.Set(header, value)
+		ctx.Data(map[string]string{"name": "jon"})
+		ctx.Serve(nil)
+		return rec
+	}
+
+	Config.ETag = ""
+	rec := serve(echo.GET, "", "")
+	assert.Equal(t, "", rec.Header().Get("ETag"))
+
+	Config.ETag = ETagStrong
+	rec = serve(echo.GET, "", "")
+	etag := rec.Header().Get("ETag")
+	assert.Len(t, etag, 34)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(echo.GET, "If-None-Match", `"other", `+etag)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Equal(t, etag, rec.Header().Get("ETag"))
+	assert.Equal(t, "", rec.Body.String())
+
+	rec = serve(echo.HEAD, "If-None-Match", etag)
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = serve(echo.GET, "If-None-Match", `"other"`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	rec = serve(echo.POST, "If-None-Match", etag)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	Config.ETag = ETagWeak
+	rec = serve(echo.GET, "If-None-Match", etag)
+	assert.Equal(t, "W/"+etag, rec.Header().Get("ETag"))
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	// failures are never not modified.
+	rec = httptest.NewRecorder()
+	ctx, _ := fakeContext(echo.GET, "/", "", rec)
+	ctx.Request().Header.Set("If-None-Match", "*")
+	ctx.Serve(echo.ErrNotFound)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestContextLastModified(t *testing.T) {
+	at := time.Date(2017, 1, 2, 3, 4, 5, 0, time.UTC)
+	var cases = []struct {
+		since string
+		code  int
+	}{
+		{"", http.StatusOK},
+		{"invalid", http.StatusOK},
+		{at.Format(http.TimeFormat), http.StatusNotModified},
+		{at.Add(time.Hour).Format(http.TimeFormat), http.StatusNotModified},
+		{at.Add(-time.Hour).Format(http.TimeFormat), http.StatusOK},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		ctx, _ := fakeContext(echo.GET, "/", "", rec)
+		ctx.Request().Header.Set(echo.HeaderIfModifiedSince, c.since)
+		ctx.SetLastModified(at.In(time.FixedZone("WIB", 7*3600)))
+		ctx.CacheControl("private", "max-age=60")
+		ctx.Serve(nil)
+
+		assert.Equal(t, c.code, rec.Code, c.since)
+		assert.Equal(t, "Mon, 02 Jan 2017 03:04:05 GMT", rec.Header().Get(echo.HeaderLastModified))
+		assert.Equal(t, "private, max-age=60", rec.Header().Get("Cache-Control"))
+	}
+}
+
+func TestContextIfMatch(t *testing.T) {
+	var cases = []struct {
+		header string
+		etag   string
+		err    error
+	}{
+		{"", `"a"`, nil},
+		{`"a"`, `"a"`, nil},
+		{`"b", "a"`, `"a"`, nil},
+		{"*", `"a"`, nil},
+		{`"b"`, `"a"`, ErrPreconditionFailed},
+		{`W/"a"`, `"a"`, ErrPreconditionFailed},
+	}
+
+	for _, c := range cases {
+		ctx, _ := fakeContext(echo.PUT, "/", "", httptest.NewRecorder())
+		ctx.Request().Header.Set("If-Match", c.header)
+		assert.Equal(t, c.err, ctx.IfMatch(c.etag), c.header)
+	}
+
+	rec := httptest.NewRecorder()
+	ctx, _ := fakeContext(echo.PUT, "/", "", rec)
+	ctx.SetETag("abc", true)
+	ctx.Serve(ErrPreconditionFailed)
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	assert.Equal(t, `W/"abc"`, rec.Header().Get("ETag"))
+}
+
+func TestModelETag(t *testing.T) {
+	type versioned struct {
+		Name    string
+		Version int
+	}
+	type updated struct {
+		Name      string
+		UpdatedAt time.Time
+	}
+
+	now := time.Now()
+	assert.Equal(t, ModelETag(&versioned{"jon", 1}), ModelETag(&versioned{"arya", 1}))
+	assert.NotEqual(t, ModelETag(&versioned{"jon", 1}), ModelETag(&versioned{"jon", 2}))
+	assert.Equal(t, ModelETag(&updated{"jon", now}), ModelETag(updated{"arya", now}))
+	assert.NotEqual(t, ModelETag(&updated{"jon", now}), ModelETag(&updated{"jon", now.Add(time.Second)}))
+	assert.Equal(t, ModelETag(map[string]string{"name": "jon"}), ModelETag(map[string]string{"name": "jon"}))
+	assert.NotEqual(t, ModelETag(map[string]string{"name": "jon"}), ModelETag(map[string]string{"name": "arya"}))
+}
+
+func TestResourceConditional(t *testing.T) {
+	if !assert.NoError(t, orm.RunSyncdb("default", true, false)) {
+		return
+	}
+
+	u := &resourceUser{Name: "jon", Email: "jon@qasico.com", Company: 1}
+	orm.NewOrm().Insert(u)
+
+	e := New()
+	(&Resource{Model: new(resourceUser)}).URLMapping(e.Group("/user"))
+
+	request := func(method string, etag string, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/user/"+u.ID.String(), strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set("If-None-Match", etag)
+		req.Header.Set("If-Match", etag)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(echo.GET, "", "")
+	etag := rec.Header().Get("ETag")
+	assert.Equal(t, ModelETag(u), etag)
+
+	rec = request(echo.GET, etag, "")
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+
+	rec = request(echo.PUT, etag, `{"name":"jon snow"}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+
+	// the data was changed since the etag.
+	rec = request(echo.PUT, etag, `{"name":"jon"}`)
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+	rec = request(echo.DELETE, etag, "")
+	assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+}
@@ -3,7 +3,11 @@ package irhabi
 import (
 	"io"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/alfatih/irhabi/common/log"
+	appconfig "github.com/alfatih/irhabi/config"
 	"github.com/alfatih/irhabi/env"
 )
 
@@ -17,15 +21,54 @@ var (
 
 // Application configuration variable
 type config struct {
-	DebugMode  bool   // Switch debug mode for production or development
-	JwtSecret  string // Secret key for Json web token algorithm
-	GzipEnable bool   // State of gzip compression
-	Host       string // IP Application will run, default is 0.0.0.0:8080
-	DbEngine   string // Database engines
-	DbHost     string // IP Database server, default is 0.0.0.0:3306
-	DbName     string // Database name will be used
-	DbUser     string // Database username
-	DbPassword string // Database password
+	DebugMode            bool          // Switch debug mode for production or development
+	JwtSecret            string        // Secret key for Json web token algorithm, required outside debug mode
+	JwtAlgorithm         string        // Json web token signing algorithm, HS256, RS256 or ES256
+	JwtKeyID             string        // Key id of Json web token signing key
+	JwtPrivateKey        string        // PEM private key file for RS256 and ES256
+	JwtPublicKey         string        // PEM public key file for RS256 and ES256
+	JwtLifetime          time.Duration // Life time of Json web token, default is 72 hours
+	JwtRefreshLifetime   time.Duration // Life time of refresh token, default is 720 hours
+	GzipEnable           bool          // State of gzip compression
+	GzipLevel            int           // Level of gzip compression, default is -1 (default compression)
+	CorsOrigins          []string      // Allowed origins of CORS, default is *
+	CorsMethods          []string      // Allowed methods of CORS
+	CorsHeaders          []string      // Allowed headers of CORS, empty will allow requested headers
+	SecureXSS            string        // X-XSS-Protection header value
+	SecureNosniff        string        // X-Content-Type-Options header value
+	SecureXFrame         string        // X-Frame-Options header value
+	SecureHSTSMaxAge     int           // Strict-Transport-Security max age in seconds, 0 is disabled
+	SecureCSP            string        // Content-Security-Policy header value
+	BodyLimit            string        // Maximum size of request body e.g 4M, empty is unlimited
+	RequestTimeout       time.Duration // Maximum duration of request, 0 is unlimited
+	MetricsRoute         string        // Route serving prometheus metrics e.g /metrics, empty is disabled
+	PaginationLinkHeader bool          // Send RFC 5988 Link header on paginated responses
+	LegacyConditions     bool          // Accept legacy conditions query param beside filter expression, default is true
+	CursorSecret         string        // Secret key to sign cursors of keyset pagination, default is random on each start
+	IDKey                string        // Secret key of obfuscated ids e.g orm.ID, default is random on each start
+	ExportChunkSize      int           // Rows of each query when streaming exports, default is 1000
+	ETag                 string        // Generate ETag of GET responses, strong or weak, empty is disabled
+	Locale               string        // Default locale of the error messages, default is en
+	ErrorFormat          string        // Format of errors in responses, legacy or structured
+	HealthRoutes         bool          // Serve /livez, /healthz and /readyz
+	HealthTimeout        time.Duration // Maximum duration of each health check, default is 3 seconds
+	HealthCacheTTL       time.Duration // Time the health checks result is cached, default is 2 seconds
+	Host                 string        // IP Application will run, default is 0.0.0.0:8080
+	ShutdownTimeout      time.Duration // Time to wait in-flight requests when shutting down, default is 30 seconds
+	ShutdownDelay        time.Duration // Time readiness is failing before stop accepting requests when shutting down
+	GracefulRestart      bool          // Restart server without closing listener socket on SIGHUP
+	DbEngine             string        // Database engines
+	DbHost               string        // IP Database server, default is 0.0.0.0:3306
+	DbName               string        // Database name will be used
+	DbUser               string        // Database username
+	DbPassword           string        // Database password
+	DbParams             string        // Additional params of database data source e.g charset=utf8mb4
+	DbTimeZone           string        // Time zone of database connection, default is Asia/Jakarta
+	DbOrmTimeZone        bool          // DB_TIMEZONE is set, so the time zone is also used by the orm
+	DbMaxIdleConns       int           // Maximum idle database connections, 0 is the driver default
+	DbMaxOpenConns       int           // Maximum open database connections, 0 is unlimited
+	DbConnMaxLifetime    time.Duration // Maximum amount of time a database connection may be reused, 0 is unlimited
+	Databases            []*Database   // Other database aliases listed on DB_ALIASES e.g read replicas
 }
 
 // loadConfig set config value from environment variable.
@@ -34,18 +77,83 @@ func loadConfig() *config {
 	c := new(config)
 
 	c.DebugMode = env.GetBool("APP_DEBUGMODE", true)
-	c.JwtSecret = env.GetString("APP_JWT_SECRET", "V3ryF*ck1ngS3cur3")
+	c.JwtSecret = env.GetString("APP_JWT_SECRET", "")
+	c.JwtAlgorithm = env.GetString("APP_JWT_ALGORITHM", "HS256")
+	c.JwtKeyID = env.GetString("APP_JWT_KID", "")
+	c.JwtPrivateKey = env.GetString("APP_JWT_PRIVATE_KEY", "")
+	c.JwtPublicKey = env.GetString("APP_JWT_PUBLIC_KEY", "")
+	c.JwtLifetime = time.Duration(env.GetInt("APP_JWT_LIFETIME", 72)) * time.Hour
+	c.JwtRefreshLifetime = time.Duration(env.GetInt("APP_JWT_REFRESH_LIFETIME", 720)) * time.Hour
 	c.GzipEnable = env.GetBool("APP_GZIP", false)
+	c.GzipLevel = env.GetInt("APP_GZIP_LEVEL", -1)
+	c.CorsOrigins = splitList(env.GetString("APP_CORS_ORIGINS", "*"))
+	c.CorsMethods = splitList(env.GetString("APP_CORS_METHODS", "GET,HEAD,PUT,PATCH,POST,DELETE"))
+	c.CorsHeaders = splitList(env.GetString("APP_CORS_HEADERS", ""))
+	c.SecureXSS = env.GetString("APP_SECURE_XSS", "1; mode=block")
+	c.SecureNosniff = env.GetString("APP_SECURE_NOSNIFF", "nosniff")
+	c.SecureXFrame = env.GetString("APP_SECURE_XFRAME", "SAMEORIGIN")
+	c.SecureHSTSMaxAge = env.GetInt("APP_SECURE_HSTS_MAXAGE", 0)
+	c.SecureCSP = env.GetString("APP_SECURE_CSP", "")
+	c.BodyLimit = env.GetString("APP_BODY_LIMIT", "")
+	c.RequestTimeout = time.Duration(env.GetInt("APP_REQUEST_TIMEOUT", 0)) * time.Second
+	c.MetricsRoute = env.GetString("APP_METRICS_ROUTE", "")
+	c.PaginationLinkHeader = env.GetBool("APP_PAGINATION_LINK_HEADER", false)
+	c.LegacyConditions = env.GetBool("APP_LEGACY_CONDITIONS", true)
+	c.CursorSecret = env.GetString("APP_CURSOR_SECRET", "")
+	c.IDKey = env.GetString("APP_ID_KEY", "")
+	c.ExportChunkSize = env.GetInt("APP_EXPORT_CHUNK_SIZE", 1000)
+	c.ETag = env.GetString("APP_ETAG", "")
+	c.Locale = env.GetString("APP_LOCALE", "en")
+	c.ErrorFormat = env.GetString("APP_ERROR_FORMAT", ErrorFormatLegacy)
+	c.HealthRoutes = env.GetBool("APP_HEALTH_ROUTES", false)
+	c.HealthTimeout = time.Duration(env.GetInt("APP_HEALTH_TIMEOUT", 3)) * time.Second
+	c.HealthCacheTTL = time.Duration(env.GetInt("APP_HEALTH_CACHE_TTL", 2)) * time.Second
 	c.Host = env.GetString("SERVER_HOST", "0.0.0.0:8500")
+	c.ShutdownTimeout = time.Duration(env.GetInt("SERVER_SHUTDOWN_TIMEOUT", 30)) * time.Second
+	c.ShutdownDelay = time.Duration(env.GetInt("SERVER_SHUTDOWN_DELAY", 0)) * time.Second
+	c.GracefulRestart = env.GetBool("SERVER_GRACEFUL_RESTART", false)
 	c.DbEngine = env.GetString("DB_ENGINE", "mysql")
 	c.DbHost = env.GetString("DB_HOST", "0.0.0.0:3306")
-	//c.DbName = env.GetString("DB_NAME", "konektifa_app")
+	c.DbName = env.GetString("DB_NAME", "konektifa_app")
 	c.DbUser = env.GetString("DB_USER", "root")
 	c.DbPassword = env.GetString("DB_PASS", "")
+	c.DbParams = env.GetString("DB_PARAMS", "")
+	c.DbOrmTimeZone = os.Getenv("DB_TIMEZONE") != ""
+	c.DbTimeZone = env.GetString("DB_TIMEZONE", "Asia/Jakarta")
+	c.DbMaxIdleConns = env.GetInt("DB_MAX_IDLE_CONNS", 0)
+	c.DbMaxOpenConns = env.GetInt("DB_MAX_OPEN_CONNS", 0)
+	c.DbConnMaxLifetime = time.Duration(env.GetInt("DB_CONN_MAX_LIFETIME", 0)) * time.Second
+	c.Databases = loadDatabases(c)
 
 	return c
 }
 
+// LoadConfig loads the application config struct from the default tags, the config files,
+// .env file, environment variables and command line arguments, see config.Loader.
+// The effective config is logged with the secrets redacted on debug mode.
+func LoadConfig(v interface{}, configs ...appconfig.Configer) error {
+	if err := appconfig.Load(v, configs...); err != nil {
+		return err
+	}
+
+	if IsDebug() {
+		log.Info("loaded config:\n%s", appconfig.Dump(v))
+	}
+
+	return nil
+}
+
+// splitList split comma separated values of config.
+func splitList(v string) (l []string) {
+	for _, i := range strings.Split(v, ",") {
+		if i = strings.TrimSpace(i); i != "" {
+			l = append(l, i)
+		}
+	}
+
+	return l
+}
+
 // IsDebug returns true if the framework is running in debug mode.
 // set environtment variable to release for disable debug.
 func IsDebug() bool {
@@ -24,7 +24,7 @@ func TestConfig(t *testing.T) {
 		expected interface{}
 	}{
 		{c.DebugMode, true},
-		{c.JwtSecret, "V3ryF*ck1ngS3cur3"},
+		{c.JwtSecret, ""},
 		{c.GzipEnable, false},
 		{c.Host, "0.0.0.0:8080"},
 		{c.DbEngine, "mysql"},
@@ -46,3 +46,20 @@ func TestIsDebugging(t *testing.T) {
 	Config.DebugMode = false
 	assert.False(t, IsDebug())
 }
+
+func TestLoadConfig(t *testing.T) {
+	type appConfig struct {
+		Name   string `default:"irhabi" valid:"required"`
+		Secret string `valid:"required"`
+	}
+
+	os.Setenv("SECRET", "")
+	c := new(appConfig)
+	assert.Error(t, LoadConfig(c))
+
+	os.Setenv("SECRET", "s3cr3t")
+	defer os.Unsetenv("SECRET")
+	assert.NoError(t, LoadConfig(c))
+	assert.Equal(t, "irhabi", c.Name)
+	assert.Equal(t, "s3cr3t", c.Secret)
+}
@@ -1,13 +1,24 @@
 package irhabi
 
 import (
+	"encoding/json"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/Sirupsen/logrus"
+	"github.com/alfatih/irhabi/common/log"
+	"github.com/alfatih/irhabi/irhabi/mw"
 	"github.com/alfatih/irhabi/orm"
+	"github.com/alfatih/irhabi/validation"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/labstack/echo"
 )
 
+// headerAcceptLanguage request header of the preferred locales.
+const headerAcceptLanguage = "Accept-Language"
+
 // Context is custom echo.Context
 // has defined as middleware.
 type Context struct {
@@ -20,8 +31,18 @@ func NewContext(c echo.Context) *Context {
 	return &Context{c, NewResponse()}
 }
 
-// Data set data and total into response format
+// Data set data and total into response format,
+// page of orm.QuerySeter.AllCursor is set with the cursor pagination meta and links.
 func (c *Context) Data(data interface{}, total ...int64) {
+	if p, ok := data.(*orm.CursorPage); ok {
+		c.PaginateCursor(p.Data, p.Next, p.Prev)
+		if len(total) > 0 {
+			c.responseFormat.Total = total[0]
+			c.responseFormat.Meta.Total = total[0]
+		}
+		return
+	}
+
 	c.responseFormat.SetData(data, total...)
 }
 
@@ -36,18 +57,39 @@ func (c *Context) Failure(fail ...string) {
 func (c *Context) Serve(e error) (err error) {
 	c.responseFormat.Code = http.StatusOK
 	if e != nil {
+		c.responseFormat.Locale = c.Locale()
 		c.responseFormat.SetError(e)
 	}
 
+	// sparse fieldsets, only the requested fields are serialized,
+	// data that can not be serialized fails as the fields of the query.
+	if fields := c.QueryParam("fields"); fields != "" && e == nil && len(c.responseFormat.Errors) == 0 && c.responseFormat.Data != nil {
+		if d, fe := sparseFields(c.responseFormat.Data, strings.Split(fields, ",")); fe != nil {
+			c.responseFormat.Errors = map[string]string{"fields": fe.Error()}
+		} else {
+			c.responseFormat.Data = d
+		}
+	}
+
+	// failures that set without error are validation errors,
+	// errors keep the status of their mapping.
 	if len(c.responseFormat.Errors) > 0 {
 		c.responseFormat.Status = HTTPResponseFail
-		c.responseFormat.Code = http.StatusUnprocessableEntity
-		c.responseFormat.Message = http.StatusText(http.StatusUnprocessableEntity)
+		if e == nil {
+			c.responseFormat.Code = http.StatusUnprocessableEntity
+			c.responseFormat.Message = http.StatusText(http.StatusUnprocessableEntity)
+		}
 		c.responseFormat.Data = nil
 		c.responseFormat.Total = 0
+		c.responseFormat.Meta = nil
+		c.responseFormat.Links = nil
 	}
 
-	if c.Request().Method == echo.HEAD || c.Request().Method == echo.OPTIONS {
+	// conditional requests, successful GET and HEAD that not modified has no body.
+	method := c.Request().Method
+	if c.responseFormat.Code == http.StatusOK && (method == echo.GET || method == echo.HEAD) && c.notModified() {
+		err = c.NoContent(http.StatusNotModified)
+	} else if method == echo.HEAD || method == echo.OPTIONS {
 		err = c.NoContent(http.StatusNoContent)
 	} else {
 		err = c.JSON(c.responseFormat.Code, c.responseFormat)
@@ -58,6 +100,44 @@ func (c *Context) Serve(e error) (err error) {
 	return
 }
 
+// Locale returns locale of the messages catalog that most preferred
+// by Accept-Language header e.g id for "id-ID,id;q=0.9,en;q=0.8",
+// default is Config.Locale.
+func (c *Context) Locale() string {
+	type locale struct {
+		tag string
+		q   float64
+	}
+
+	var ls []locale
+	for _, i := range strings.Split(c.Request().Header.Get(headerAcceptLanguage), ",") {
+		p := strings.Split(strings.TrimSpace(i), ";")
+		l := locale{tag: strings.ToLower(strings.TrimSpace(p[0])), q: 1}
+		for _, x := range p[1:] {
+			if x = strings.TrimSpace(x); strings.HasPrefix(x, "q=") {
+				l.q, _ = strconv.ParseFloat(x[2:], 64)
+			}
+		}
+
+		if l.tag != "" && l.tag != "*" && l.q > 0 {
+			ls = append(ls, l)
+		}
+	}
+	sort.SliceStable(ls, func(i, j int) bool { return ls[i].q > ls[j].q })
+
+	for _, l := range ls {
+		if validation.HasCatalog(l.tag) {
+			return l.tag
+		}
+
+		if i := strings.Index(l.tag, "-"); i > 0 && validation.HasCatalog(l.tag[:i]) {
+			return l.tag[:i]
+		}
+	}
+
+	return Config.Locale
+}
+
 // RequestQuery set query param into orm so the repository
 // can use the data.
 func (c *Context) RequestQuery() *orm.RequestQuery {
@@ -70,6 +150,28 @@ func (c *Context) RequestQuery() *orm.RequestQuery {
 	return rq.ReadFromContext(c.QueryParams())
 }
 
+// ParamID returns real id of the obfuscated path param e.g /users/:id,
+// modified value returns *common.DecryptionError that served as not found.
+func (c *Context) ParamID(name string) (orm.ID, error) {
+	return orm.ParseID(c.Param(name))
+}
+
+// RequestID returns id of the request that set by mw.RequestID.
+func (c *Context) RequestID() string {
+	id, _ := c.Get(mw.RequestIDKey).(string)
+	return id
+}
+
+// Log returns log entry of the request, that having the request id set by mw.RequestID.
+func (c *Context) Log() *logrus.Entry {
+	return log.FromContext(c.Request().Context())
+}
+
+// Orm returns new orm that the queries are logged with the request id.
+func (c *Context) Orm() orm.Ormer {
+	return orm.NewOrmWithContext(c.Request().Context())
+}
+
 // JwtUsers get a user sessions that having jwt token in
 // request header and checked again the model.
 func (c *Context) JwtUsers(model jwtUser) interface{} {
@@ -86,6 +188,21 @@ func (c *Context) JwtUsers(model jwtUser) interface{} {
 	return nil
 }
 
+// JwtClaims decode claims of jwt token in request header
+// into the typed claims.
+func (c *Context) JwtClaims(claims jwt.Claims) error {
+	if u, ok := c.Get("user").(*jwt.Token); ok {
+		b, err := json.Marshal(u.Claims)
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(b, claims)
+	}
+
+	return ErrJwtMissing
+}
+
 // jwtUser model user jwt token interface
 // to check is the id given valid as users.
 type jwtUser interface {
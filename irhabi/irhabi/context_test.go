@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/alfatih/irhabi/common"
 	"github.com/alfatih/irhabi/orm"
 	"github.com/alfatih/irhabi/validation"
 	"github.com/labstack/echo"
@@ -157,15 +158,20 @@ func TestContextRequestQuery(t *testing.T) {
 }
 
 func TestContextRequestQueryWithDecryption(t *testing.T) {
+	e := New()
+	id := common.Encrypt(1)
 	var cases = []struct {
 		query    string
 		expected []map[string]string
+		valid    bool
 	}{
-		{"/?conditions=name:alif%252Cemail:alifamri@qasico.com", []map[string]string{{"name": "alif", "email": "alifamri@qasico.com"}}},
-		{"/?conditions=id.e:65536%252Citem.item_category_id.id.e:65536%252Cis_archived:1%252Cidx:1", []map[string]string{{"id": "1", "item.item_category_id.id": "1", "is_archived": "1", "idx": "1"}}},
+		{"/?conditions=name:alif%252Cemail:alifamri@qasico.com", []map[string]string{{"name": "alif", "email": "alifamri@qasico.com"}}, true},
+		{"/?conditions=id.e:" + id + "%252Citem.item_category_id.id.e:" + id + "%252Cis_archived:1%252Cidx:1", []map[string]string{{"id": "1", "item.item_category_id.id": "1", "is_archived": "1", "idx": "1"}}, true},
+		{"/?conditions=id.e:65536", []map[string]string{{}}, false},
+		{"/?filter=id.e:in:" + id + "|" + common.Encrypt(2), []map[string]string{}, true},
+		{"/?filter=id.e:eq:65536", []map[string]string{}, false},
 	}
 
-	e := New()
 	for _, i := range cases {
 		req, _ := http.NewRequest(echo.GET, i.query, nil)
 		rec := httptest.NewRecorder()
@@ -173,6 +179,201 @@ func TestContextRequestQueryWithDecryption(t *testing.T) {
 		c := NewContext(ctx)
 
 		qs := c.RequestQuery()
-		assert.Equal(t, i.expected, qs.Conditions)
+		assert.Equal(t, i.expected, qs.Conditions, i.query)
+		assert.Equal(t, i.valid, qs.Validate(nil) == nil, i.query)
+	}
+}
+
+func TestContextParamID(t *testing.T) {
+	e := New()
+	req, _ := http.NewRequest(echo.GET, "/", nil)
+	rec := httptest.NewRecorder()
+	c := NewContext(e.NewContext(req, rec))
+	c.SetParamNames("id")
+
+	c.SetParamValues(common.Encrypt(10))
+	id, err := c.ParamID("id")
+	assert.NoError(t, err)
+	assert.Equal(t, orm.ID(10), id)
+
+	c.SetParamValues("10")
+	_, err = c.ParamID("id")
+	if assert.Error(t, err) {
+		assert.NoError(t, c.Serve(err))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	}
+}
+
+type rulesUser struct {
+	ID       int64
+	Name     string
+	Password string
+}
+
+func (m *rulesUser) QueryRules() orm.QueryRules {
+	return orm.QueryRules{
+		Filters:    map[string][]string{"name": {"exact", "icontains"}, "group.id": nil},
+		Sorts:      []string{"id", "name"},
+		Embeds:     []string{"group.company"},
+		EmbedDepth: 2,
+	}
+}
+
+func TestContextRequestQueryRules(t *testing.T) {
+	var cases = []struct {
+		query  string
+		errors map[string]string
+	}{
+		{"/?conditions=name:alif%252COr.group.id.in:1.2&orderby=-name,id&embeds=group,group.company", nil},
+		{"/?conditions=name.icontains:ali|AndNot.group.id:1", nil},
+		{"/?conditions=password.startswith:a%252Cname.startswith:a", map[string]string{
+			"conditions.password":        "The password field is not filterable.",
+			"conditions.name.startswith": "The name field is not filterable with startswith operator.",
+		}},
+		{"/?orderby=-password&embeds=group.company.owner,tags", map[string]string{
+			"orderby.password":           "The password field is not sortable.",
+			"embeds.group.company.owner": "The group.company.owner embeds is deeper than 2.",
+			"embeds.tags":                "The tags field is not embeddable.",
+		}},
+		{"/?fields=name,group.name,group.company.name", nil},
+		{"/?fields=tags.name,group.company.owner.name", map[string]string{
+			"fields.tags.name":                "The tags.name field is not embeddable.",
+			"fields.group.company.owner.name": "The group.company.owner.name field is deeper than 2 embeds.",
+		}},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		ctx, _ := fakeContext(echo.GET, c.query, "", rec)
+
+		err := ctx.RequestQuery().Validate(new(rulesUser))
+		if c.errors == nil {
+			assert.NoError(t, err, c.query)
+			continue
+		}
+
+		if assert.IsType(t, &orm.FieldsError{}, err, c.query) {
+			assert.Equal(t, c.errors, err.(*orm.FieldsError).Errors, c.query)
+		}
+
+		if assert.NoError(t, ctx.Serve(err)) {
+			assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		}
+	}
+
+	assert.NoError(t, (&orm.RequestQuery{OrderBy: []string{"password"}}).Validate(struct{}{}))
+}
+
+func TestContextRequestQueryFilter(t *testing.T) {
+	legacy := Config.LegacyConditions
+	defer func() {
+		Config.LegacyConditions = legacy
+		orm.LegacyConditions = legacy
+	}()
+
+	rec := httptest.NewRecorder()
+	ctx, _ := fakeContext(echo.GET, "/?filter=(name:exact:alif,or(group.id:in:1|2,name:icontains:amri))&conditions=name:alif", "", rec)
+	rq := ctx.RequestQuery()
+	if assert.NotNil(t, rq.Filter) {
+		assert.NoError(t, rq.Validate(new(rulesUser)))
+		assert.False(t, rq.GetCondition().IsEmpty())
+		assert.Len(t, rq.Conditions, 1)
+	}
+
+	Config.LegacyConditions = false
+	Setup()
+	ctx, _ = fakeContext(echo.GET, "/?filter=name:eq:alif&conditions=name:alif", "", rec)
+	assert.Empty(t, ctx.RequestQuery().Conditions)
+
+	ctx, _ = fakeContext(echo.GET, "/?filter=password:startswith:a,name:startswith:a", "", rec)
+	err := ctx.RequestQuery().Validate(new(rulesUser))
+	if assert.IsType(t, &orm.FieldsError{}, err) {
+		assert.Equal(t, map[string]string{
+			"filter.password":        "The password field is not filterable.",
+			"filter.name.startswith": "The name field is not filterable with startswith operator.",
+		}, err.(*orm.FieldsError).Errors)
+	}
+
+	rec = httptest.NewRecorder()
+	ctx, _ = fakeContext(echo.GET, "/?filter=(name:eq:alif", "", rec)
+	err = ctx.RequestQuery().Validate(nil)
+	if assert.IsType(t, &orm.FilterError{}, err) && assert.NoError(t, ctx.Serve(err)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.JSONEq(t, `{"status":"fail","message":"Unprocessable Entity","errors":{"filter":"expected ')' at position 14"}}`, rec.Body.String())
+	}
+}
+
+func TestContextRequestQuerySearch(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ctx, _ := fakeContext(echo.GET, "/?q=+jon%20snow+&filter=name:exact:jon", "", rec)
+	assert.Equal(t, "jon snow", ctx.RequestQuery().Search)
+
+	ctx, _ = fakeContext(echo.GET, "/?q=", "", rec)
+	assert.Empty(t, ctx.RequestQuery().Search)
+}
+
+func TestContextLocale(t *testing.T) {
+	var tests = []struct {
+		header   string
+		expected string
+	}{
+		{"", "en"},
+		{"id-ID,id;q=0.9,en;q=0.8", "id"},
+		{"en-US;q=0.5, id;q=0.8", "id"},
+		{"fr-CA, id;q=0", "en"},
+		{"*, ID", "id"},
+	}
+
+	for _, test := range tests {
+		ctx, _ := fakeContext(echo.GET, "/", "", httptest.NewRecorder())
+		ctx.Request().Header.Set(headerAcceptLanguage, test.header)
+		assert.Equal(t, test.expected, ctx.Locale(), test.header)
+	}
+}
+
+func TestContextServeLocalizedErrors(t *testing.T) {
+	defer func(f string) { Config.ErrorFormat = f }(Config.ErrorFormat)
+
+	type user struct {
+		Name string `json:"name" valid:"required"`
+		Age  int    `json:"age" valid:"gte:18"`
+	}
+	o := validation.New().Struct(user{Age: 17})
+
+	rec := httptest.NewRecorder()
+	ctx, _ := fakeContext(echo.POST, "/", "", rec)
+	ctx.Request().Header.Set(headerAcceptLanguage, "id-ID,id;q=0.9")
+	if assert.NoError(t, ctx.Serve(o)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.JSONEq(t, `{"status":"fail","message":"Unprocessable Entity","errors":{"name":"Kolom name wajib diisi.","age":"Kolom age tidak boleh lebih kecil dari 18"}}`, rec.Body.String())
+	}
+
+	Config.ErrorFormat = ErrorFormatStructured
+	rec = httptest.NewRecorder()
+	ctx, _ = fakeContext(echo.POST, "/", "", rec)
+	if assert.NoError(t, ctx.Serve(o)) {
+		assert.JSONEq(t, `{"status":"fail","message":"Unprocessable Entity","errors":{
+			"name":{"code":"required","message":"The name field is required."},
+			"age":{"code":"gte","params":{"min":18},"message":"The age may not be less than 18"}}}`, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	ctx, _ = fakeContext(echo.POST, "/", "", rec)
+	if assert.NoError(t, ctx.Serve(ErrDataExists("email", "The email is already registered."))) {
+		assert.JSONEq(t, `{"status":"fail","message":"Unprocessable Entity","errors":{"email":{"code":"exists","message":"The email is already registered."}}}`, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	ctx, _ = fakeContext(echo.POST, "/", "", rec)
+	ctx.Failure("name", "The name is already taken.")
+	if assert.NoError(t, ctx.Serve(nil)) {
+		assert.JSONEq(t, `{"status":"fail","message":"Unprocessable Entity","errors":{"name":{"code":"invalid","message":"The name is already taken."}}}`, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	ctx, _ = fakeContext(echo.GET, "/", "", rec)
+	ctx.Data(map[string]int{"id": 1})
+	if assert.NoError(t, ctx.Serve(nil)) {
+		assert.JSONEq(t, `{"status":"success","data":{"id":1}}`, rec.Body.String())
 	}
 }
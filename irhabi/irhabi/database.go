@@ -1,23 +1,279 @@
 package irhabi
 
 import (
+	"database/sql"
 	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/alfatih/irhabi/common/log"
+	"github.com/alfatih/irhabi/env"
 	"github.com/alfatih/irhabi/orm"
 	// mysql driver
 	_ "github.com/go-sql-driver/mysql"
 )
 
+// Database connection config of an orm database alias.
+type Database struct {
+	Alias           string        // Name of the orm alias e.g default, replica
+	Engine          string        // Database engine, mysql, tidb, postgres, sqlite3, oci8 or ora
+	Host            string        // IP Database server e.g 0.0.0.0:3306, storage of the embedded tidb e.g goleveldb
+	Name            string        // Database name, path of the database file for sqlite3 and the embedded tidb
+	User            string        // Database username
+	Password        string        // Database password
+	Params          string        // Additional params of data source e.g charset=utf8mb4&timeout=5s
+	TimeZone        string        // Time zone of the connection e.g Asia/Jakarta, the loc or timezone param of data source
+	OrmTimeZone     bool          // Set the TimeZone as time zone of the orm alias, otherwise it is detected from the database
+	MaxIdleConns    int           // Maximum idle connections, 0 is the driver default
+	MaxOpenConns    int           // Maximum open connections, 0 is unlimited
+	ConnMaxLifetime time.Duration // Maximum amount of time a connection may be reused, 0 is unlimited
+	ReplicaOf       string        // Alias of the primary database when it is a read replica
+}
+
+var (
+	// driverImports import path of the sql driver for each engine.
+	driverImports = map[string]string{
+		"mysql":    "github.com/go-sql-driver/mysql",
+		"tidb":     "github.com/pingcap/tidb",
+		"postgres": "github.com/lib/pq",
+		"sqlite3":  "github.com/mattn/go-sqlite3",
+		"oci8":     "github.com/mattn/go-oci8",
+		"ora":      "gopkg.in/rana/ora.v4",
+	}
+
+	// replicas read replica aliases of each primary alias.
+	replicas = struct {
+		sync.RWMutex
+		aliases map[string][]string
+		next    uint32
+	}{aliases: make(map[string][]string)}
+)
+
 // DbSetup registering database connection
 // by reading database config variables.
+// The default alias is configured by DB_* variables, and the other aliases
+// listed on DB_ALIASES by DB_<ALIAS>_* variables, see Config.Databases.
 func DbSetup() error {
+	Setup()
+
 	orm.Debug = IsDebug()
 	orm.DebugLog = log.Log
 	orm.DefaultTimeLoc = time.Local
 	orm.DefaultRelsDepth = 3
 
-	ds := fmt.Sprintf("%s:%s@tcp(%s)/%s?%s", Config.DbUser, Config.DbPassword, Config.DbHost, Config.DbName, "charset=utf8&loc=Asia%2FJakarta")
-	return orm.RegisterDataBase("default", Config.DbEngine, ds)
+	dbs := append([]*Database{defaultDatabase()}, Config.Databases...)
+	for _, d := range dbs {
+		if err := d.check(); err != nil {
+			return err
+		}
+	}
+
+	for _, d := range dbs {
+		if err := d.register(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DbReplica returns one of the read replica aliases of the alias in round robin,
+// the alias itself is returned when it has no replica.
+// e.g o.Using(cuxs.DbReplica("default"))
+func DbReplica(alias string) string {
+	replicas.RLock()
+	defer replicas.RUnlock()
+
+	if l := replicas.aliases[alias]; len(l) > 0 {
+		return l[int(atomic.AddUint32(&replicas.next, 1))%len(l)]
+	}
+
+	return alias
+}
+
+// DataSource returns the data source name of the database for its engine.
+func (d *Database) DataSource() string {
+	params, _ := url.ParseQuery(d.Params)
+
+	switch d.Engine {
+	case "tidb":
+		// embedded tidb of the storage url e.g goleveldb://data/app, the host of
+		// server address is defaulted to goleveldb, tidb server is connected with the mysql engine.
+		storage := d.Host
+		if storage == "" || strings.Contains(storage, ":") {
+			storage = "goleveldb"
+		}
+
+		ds := storage + "://" + d.Name
+		if len(params) > 0 {
+			ds += "?" + params.Encode()
+		}
+
+		return ds
+	case "mysql":
+		setDefault(params, "charset", "utf8")
+		if d.TimeZone != "" {
+			setDefault(params, "loc", d.TimeZone)
+		}
+
+		return fmt.Sprintf("%s:%s@tcp(%s)/%s?%s", d.User, d.Password, d.Host, d.Name, params.Encode())
+	case "postgres":
+		setDefault(params, "sslmode", "disable")
+		if d.TimeZone != "" {
+			setDefault(params, "timezone", d.TimeZone)
+		}
+
+		u := &url.URL{Scheme: "postgres", Host: d.Host, Path: "/" + d.Name, RawQuery: params.Encode()}
+		if d.User != "" {
+			u.User = url.UserPassword(d.User, d.Password)
+		}
+
+		return u.String()
+	case "sqlite3":
+		if len(params) == 0 {
+			return d.Name
+		}
+
+		sep := "?"
+		if strings.Contains(d.Name, "?") {
+			sep = "&"
+		}
+
+		return d.Name + sep + params.Encode()
+	case "oci8", "ora":
+		ds := fmt.Sprintf("%s/%s@%s/%s", d.User, d.Password, d.Host, d.Name)
+		if len(params) > 0 {
+			ds += "?" + params.Encode()
+		}
+
+		return ds
+	}
+
+	return ""
+}
+
+// check returns error when the engine is not supported, the sql driver is not imported
+// or the config values are invalid, so the setup fails before connecting any database.
+func (d *Database) check() error {
+	imp, ok := driverImports[d.Engine]
+	if !ok {
+		return fmt.Errorf("database: engine `%s` of alias `%s` is not supported", d.Engine, d.Alias)
+	}
+
+	if !hasDriver(d.Engine) {
+		return fmt.Errorf("database: driver `%s` of alias `%s` is not imported, add `import _ \"%s\"`", d.Engine, d.Alias, imp)
+	}
+
+	if _, err := url.ParseQuery(d.Params); err != nil {
+		return fmt.Errorf("database: invalid params of alias `%s`, %s", d.Alias, err.Error())
+	}
+
+	if _, err := time.LoadLocation(d.TimeZone); err != nil {
+		return fmt.Errorf("database: invalid time zone of alias `%s`, %s", d.Alias, err.Error())
+	}
+
+	return nil
+}
+
+// register registering the database alias into orm, and then
+// configure the pool, time zone and replica of the alias.
+func (d *Database) register() (err error) {
+	if err = orm.RegisterDataBase(d.Alias, d.Engine, d.DataSource()); err != nil {
+		return
+	}
+
+	if d.MaxIdleConns > 0 {
+		orm.SetMaxIdleConns(d.Alias, d.MaxIdleConns)
+	}
+
+	if d.MaxOpenConns > 0 {
+		orm.SetMaxOpenConns(d.Alias, d.MaxOpenConns)
+	}
+
+	if d.ConnMaxLifetime > 0 {
+		orm.SetConnMaxLifetime(d.Alias, d.ConnMaxLifetime)
+	}
+
+	if d.OrmTimeZone && d.TimeZone != "" {
+		loc, _ := time.LoadLocation(d.TimeZone)
+		if err = orm.SetDataBaseTZ(d.Alias, loc); err != nil {
+			return
+		}
+	}
+
+	if d.ReplicaOf != "" {
+		replicas.Lock()
+		replicas.aliases[d.ReplicaOf] = append(replicas.aliases[d.ReplicaOf], d.Alias)
+		replicas.Unlock()
+	}
+
+	return
+}
+
+// defaultDatabase returns the default alias from the config values.
+func defaultDatabase() *Database {
+	return &Database{
+		Alias:           "default",
+		Engine:          Config.DbEngine,
+		Host:            Config.DbHost,
+		Name:            Config.DbName,
+		User:            Config.DbUser,
+		Password:        Config.DbPassword,
+		Params:          Config.DbParams,
+		TimeZone:        Config.DbTimeZone,
+		OrmTimeZone:     Config.DbOrmTimeZone,
+		MaxIdleConns:    Config.DbMaxIdleConns,
+		MaxOpenConns:    Config.DbMaxOpenConns,
+		ConnMaxLifetime: Config.DbConnMaxLifetime,
+	}
+}
+
+// loadDatabases returns the database aliases listed on DB_ALIASES e.g replica,report
+// each of them is configured by DB_<ALIAS>_* variables e.g DB_REPLICA_HOST,
+// the missing variables are inherited from the default alias.
+func loadDatabases(c *config) (dbs []*Database) {
+	for _, alias := range splitList(env.GetString("DB_ALIASES", "")) {
+		p := "DB_" + strings.ToUpper(strings.Replace(alias, "-", "_", -1)) + "_"
+		// env.GetString sets the default value, so the explicit time zone is read first.
+		tz := os.Getenv(p + "TIMEZONE")
+		dbs = append(dbs, &Database{
+			Alias:           alias,
+			Engine:          env.GetString(p+"ENGINE", c.DbEngine),
+			Host:            env.GetString(p+"HOST", c.DbHost),
+			Name:            env.GetString(p+"NAME", c.DbName),
+			User:            env.GetString(p+"USER", c.DbUser),
+			Password:        env.GetString(p+"PASS", c.DbPassword),
+			Params:          env.GetString(p+"PARAMS", c.DbParams),
+			TimeZone:        env.GetString(p+"TIMEZONE", c.DbTimeZone),
+			OrmTimeZone:     c.DbOrmTimeZone || tz != "",
+			MaxIdleConns:    env.GetInt(p+"MAX_IDLE_CONNS", c.DbMaxIdleConns),
+			MaxOpenConns:    env.GetInt(p+"MAX_OPEN_CONNS", c.DbMaxOpenConns),
+			ConnMaxLifetime: time.Duration(env.GetInt(p+"CONN_MAX_LIFETIME", int(c.DbConnMaxLifetime/time.Second))) * time.Second,
+			ReplicaOf:       env.GetString(p+"REPLICA_OF", ""),
+		})
+	}
+
+	return
+}
+
+// hasDriver returns true when the sql driver is imported.
+func hasDriver(name string) bool {
+	for _, d := range sql.Drivers() {
+		if d == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setDefault set the param when it is not exists.
+func setDefault(params url.Values, key, value string) {
+	if _, ok := params[key]; !ok {
+		params.Set(key, value)
+	}
 }
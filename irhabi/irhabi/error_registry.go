@@ -0,0 +1,244 @@
+package irhabi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/alfatih/irhabi/common"
+	"github.com/alfatih/irhabi/orm"
+	"github.com/alfatih/irhabi/validation"
+	"github.com/labstack/echo"
+)
+
+// Policy of the error message exposure in responses.
+const (
+	// MessageDebug expose the error message only on debug mode,
+	// otherwise the http status text is used.
+	MessageDebug MessagePolicy = iota
+
+	// MessageHidden always using the http status text.
+	MessageHidden
+
+	// MessageExposed always expose the error message.
+	MessageExposed
+)
+
+// DefaultErrorRegistry registry used by ResponseFormat.SetError.
+var DefaultErrorRegistry = NewErrorRegistry()
+
+type (
+	// MessagePolicy how the error message exposed in responses.
+	MessagePolicy int
+
+	// ErrorMapping describe how the error presented in response format.
+	ErrorMapping struct {
+		Status  int                               // HTTP status code of the response
+		Code    string                            // Stable machine readable error code
+		Message MessagePolicy                     // Exposure of the error message
+		Errors  func(err error) map[string]string // Field errors of the matched error, optional
+		// Code, params and message of the field errors, optional, it takes precedence over Errors
+		Failures func(err error) map[string]validation.Failure
+	}
+
+	// ErrorRegistry registry of error mappings, the latest
+	// registered mapping is checked first so it can override the defaults.
+	ErrorRegistry struct {
+		mu       sync.RWMutex
+		mappings []errorMatcher
+	}
+
+	// errorMatcher returns the matched error and the mapping.
+	errorMatcher func(err error) (error, ErrorMapping, bool)
+)
+
+// NewErrorRegistry creates error registry with default mappings of
+// echo.HTTPError, validation.Output, orm.OrmError, orm.ErrNoRows,
+// orm.FieldsError, orm.FilterError, DataNotExistsError and DataDuplicateError.
+func NewErrorRegistry() *ErrorRegistry {
+	r := new(ErrorRegistry)
+
+	// Error cause of http failure should return status as is the errors
+	// using standart http code.
+	r.RegisterFunc(func(err error) (ErrorMapping, bool) {
+		var he *echo.HTTPError
+		if errors.As(err, &he) {
+			return ErrorMapping{Status: he.Code}, true
+		}
+
+		return ErrorMapping{}, false
+	})
+
+	// Error cause of validation failure should return
+	// status 422 and returning all failure messages as errors.
+	r.Register((*validation.Output)(nil), ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Message: MessageHidden,
+		Errors: func(err error) map[string]string {
+			return err.(*validation.Output).Messages()
+		},
+		Failures: func(err error) map[string]validation.Failure {
+			return err.(*validation.Output).Failures()
+		},
+	})
+
+	// Error cause of error from databases.
+	// this should be treaten as bad requests.
+	r.Register((*orm.OrmError)(nil), ErrorMapping{Status: http.StatusBadRequest})
+	r.Register(orm.ErrNoRows, ErrorMapping{Status: http.StatusNotFound})
+
+	// Error cause of requesting fields that not exists or not exposed,
+	// presented as validation failure.
+	r.Register((*orm.FieldsError)(nil), ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Message: MessageHidden,
+		Errors: func(err error) map[string]string {
+			return err.(*orm.FieldsError).Errors
+		},
+	})
+
+	// Error cause of invalid filter expression.
+	r.Register((*orm.FilterError)(nil), ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Message: MessageHidden,
+		Errors: func(err error) map[string]string {
+			return map[string]string{"filter": err.Error()}
+		},
+	})
+
+	// Error cause of modified obfuscated id, the data is never exists.
+	r.Register((*common.DecryptionError)(nil), ErrorMapping{Status: http.StatusNotFound})
+
+	// Error cause of data not exists or invalid
+	// threated as Unprocessable Entity with code 422.
+	r.Register((*DataNotExistsError)(nil), ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Message: MessageHidden,
+		Errors: func(err error) map[string]string {
+			return err.(*DataNotExistsError).Errors
+		},
+		Failures: func(err error) map[string]validation.Failure {
+			return failures(err.(*DataNotExistsError).Errors, "not_exists")
+		},
+	})
+	r.Register((*DataDuplicateError)(nil), ErrorMapping{
+		Status:  http.StatusUnprocessableEntity,
+		Message: MessageHidden,
+		Errors: func(err error) map[string]string {
+			return err.(*DataDuplicateError).Errors
+		},
+		Failures: func(err error) map[string]validation.Failure {
+			return failures(err.(*DataDuplicateError).Errors, "exists")
+		},
+	})
+
+	return r
+}
+
+// Register mapping of the error, target can be an error value that matched
+// using errors.Is e.g orm.ErrNoRows, or nil pointer of the error type or
+// interface that matched using errors.As e.g (*MyError)(nil).
+func (r *ErrorRegistry) Register(target interface{}, m ErrorMapping) {
+	v := reflect.ValueOf(target)
+	if e, ok := target.(error); ok && !(v.Kind() == reflect.Ptr && v.IsNil()) {
+		r.add(func(err error) (error, ErrorMapping, bool) {
+			return err, m, errors.Is(err, e)
+		})
+		return
+	}
+
+	t := reflect.TypeOf(target)
+	if t == nil || t.Kind() != reflect.Ptr {
+		panic(fmt.Errorf("irhabi: error target %T should be an error or nil pointer of error type", target))
+	}
+
+	// pointer of interface is matching the interface.
+	if t.Elem().Kind() == reflect.Interface {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Interface && !t.Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+		panic(fmt.Errorf("irhabi: error target %v is not implementing error", t))
+	}
+
+	r.add(func(err error) (error, ErrorMapping, bool) {
+		p := reflect.New(t)
+		if errors.As(err, p.Interface()) {
+			if e, ok := p.Elem().Interface().(error); ok {
+				return e, m, true
+			}
+		}
+
+		return nil, m, false
+	})
+}
+
+// RegisterFunc register function that mapping the error dynamically.
+func (r *ErrorRegistry) RegisterFunc(fn func(err error) (ErrorMapping, bool)) {
+	r.add(func(err error) (error, ErrorMapping, bool) {
+		m, ok := fn(err)
+		return err, m, ok
+	})
+}
+
+// Lookup returns mapping of the error and the error that matched,
+// errors that not registered are treated as internal server error.
+func (r *ErrorRegistry) Lookup(err error) (ErrorMapping, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for i := len(r.mappings) - 1; i >= 0; i-- {
+		if e, m, ok := r.mappings[i](err); ok {
+			return m, e
+		}
+	}
+
+	return ErrorMapping{Status: http.StatusInternalServerError}, err
+}
+
+// add register the matcher.
+func (r *ErrorRegistry) add(m errorMatcher) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.mappings = append(r.mappings, m)
+}
+
+// failures returns failures of the field errors with the code.
+func failures(errs map[string]string, code string) map[string]validation.Failure {
+	fs := make(map[string]validation.Failure, len(errs))
+	for k, m := range errs {
+		fs[k] = validation.Failure{Code: code, Message: m}
+	}
+
+	return fs
+}
+
+// message returns message of the error based on the policy.
+func (m ErrorMapping) message(err error) interface{} {
+	if m.Message == MessageExposed || (m.Message == MessageDebug && IsDebug()) {
+		if he, ok := err.(*echo.HTTPError); ok {
+			return he.Message
+		}
+
+		return err.Error()
+	}
+
+	return http.StatusText(m.Status)
+}
+
+// RegisterError register mapping of the error into default registry,
+// see ErrorRegistry.Register, e.g:
+//
+//	irhabi.RegisterError(ErrOutOfStock, irhabi.ErrorMapping{Status: 409, Code: "out_of_stock", Message: irhabi.MessageExposed})
+//	irhabi.RegisterError((*PaymentError)(nil), irhabi.ErrorMapping{Status: 402, Code: "payment_failed"})
+func RegisterError(target interface{}, m ErrorMapping) {
+	DefaultErrorRegistry.Register(target, m)
+}
+
+// RegisterErrorFunc register function that mapping the error dynamically into default registry.
+func RegisterErrorFunc(fn func(err error) (ErrorMapping, bool)) {
+	DefaultErrorRegistry.RegisterFunc(fn)
+}
@@ -0,0 +1,108 @@
+package irhabi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alfatih/irhabi/orm"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+type paymentError struct {
+	Field string
+}
+
+func (e *paymentError) Error() string {
+	return "payment failed on " + e.Field
+}
+
+type temporary interface {
+	Temporary() bool
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "upstream timeout" }
+func (timeoutError) Temporary() bool { return true }
+
+var errOutOfStock = errors.New("item is out of stock")
+
+func TestErrorRegistry(t *testing.T) {
+	debug := Config.DebugMode
+	defer func() {
+		Config.DebugMode = debug
+	}()
+
+	r := NewErrorRegistry()
+	r.Register(errOutOfStock, ErrorMapping{Status: http.StatusConflict, Code: "out_of_stock", Message: MessageExposed})
+	r.Register((*paymentError)(nil), ErrorMapping{
+		Status: http.StatusPaymentRequired,
+		Code:   "payment_failed",
+		Errors: func(err error) map[string]string {
+			return map[string]string{err.(*paymentError).Field: "declined"}
+		},
+	})
+	r.Register((*temporary)(nil), ErrorMapping{Status: http.StatusServiceUnavailable, Code: "temporary"})
+
+	var cases = []struct {
+		err     error
+		debug   bool
+		status  int
+		code    string
+		message interface{}
+		errors  map[string]string
+	}{
+		{fmt.Errorf("checkout: %w", errOutOfStock), false, http.StatusConflict, "out_of_stock", "checkout: item is out of stock", nil},
+		{fmt.Errorf("charge: %w", &paymentError{"card"}), false, http.StatusPaymentRequired, "payment_failed", "Payment Required", map[string]string{"card": "declined"}},
+		{&paymentError{"card"}, true, http.StatusPaymentRequired, "payment_failed", "payment failed on card", map[string]string{"card": "declined"}},
+		{timeoutError{}, false, http.StatusServiceUnavailable, "temporary", "Service Unavailable", nil},
+		{orm.ErrNoRows, false, http.StatusNotFound, "", "Not Found", nil},
+		{fmt.Errorf("read: %w", orm.ErrNoRows), true, http.StatusNotFound, "", "read: No row found", nil},
+		{orm.NewOrmError("fatal"), false, http.StatusBadRequest, "", "Bad Request", nil},
+		{echo.NewHTTPError(http.StatusForbidden, "no access"), true, http.StatusForbidden, "", "no access", nil},
+		{ErrDataNotExists("name", "not exists"), true, http.StatusUnprocessableEntity, "", "Unprocessable Entity", map[string]string{"name": "not exists"}},
+		{errors.New("dial tcp: connection refused"), false, http.StatusInternalServerError, "", "Internal Server Error", nil},
+		{errors.New("dial tcp: connection refused"), true, http.StatusInternalServerError, "", "dial tcp: connection refused", nil},
+	}
+
+	for _, c := range cases {
+		Config.DebugMode = c.debug
+		m, target := r.Lookup(c.err)
+		assert.Equal(t, c.status, m.Status, c.err.Error())
+		assert.Equal(t, c.code, m.Code, c.err.Error())
+		assert.Equal(t, c.message, m.message(target), c.err.Error())
+		if c.errors != nil {
+			assert.Equal(t, c.errors, m.Errors(target), c.err.Error())
+		}
+	}
+
+	assert.Panics(t, func() {
+		r.Register(paymentError{}, ErrorMapping{})
+	})
+}
+
+func TestContextServeRegisteredError(t *testing.T) {
+	defer func(r *ErrorRegistry) { DefaultErrorRegistry = r }(DefaultErrorRegistry)
+	DefaultErrorRegistry = NewErrorRegistry()
+
+	RegisterError((*paymentError)(nil), ErrorMapping{
+		Status:  http.StatusPaymentRequired,
+		Code:    "payment_failed",
+		Message: MessageExposed,
+		Errors: func(err error) map[string]string {
+			return map[string]string{err.(*paymentError).Field: "declined"}
+		},
+	})
+
+	rec := httptest.NewRecorder()
+	ctx, _ := fakeContext(echo.POST, "/", "", rec)
+	ctx.Data("ignored")
+	if assert.NoError(t, ctx.Serve(&paymentError{"card"})) {
+		assert.Equal(t, http.StatusPaymentRequired, rec.Code)
+		assert.JSONEq(t, `{"status":"fail","code":"payment_failed","message":"payment failed on card","errors":{"card":"declined"}}`, rec.Body.String())
+	}
+}
@@ -0,0 +1,414 @@
+package irhabi
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/alfatih/irhabi/common"
+	"github.com/alfatih/irhabi/orm"
+	"github.com/labstack/echo"
+)
+
+// Formats of the exports.
+const (
+	ExportCSV    = "csv"
+	ExportXLSX   = "xlsx"
+	ExportNDJSON = "ndjson"
+)
+
+// exportTypes content type of the export formats.
+var exportTypes = map[string]string{
+	ExportCSV:    "text/csv",
+	ExportXLSX:   "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	ExportNDJSON: "application/x-ndjson",
+}
+
+type (
+	// ExportColumn column of the exports.
+	ExportColumn struct {
+		Field  string // Json key of the data, nested key is using dot e.g group.name
+		Header string // Header of the column, default is the field
+	}
+
+	// exportWriter writer of the export format.
+	exportWriter interface {
+		header(columns []ExportColumn) error
+		row(values []interface{}) error
+		flush() error
+		close() error
+	}
+)
+
+// ExportFormat returns the export format requested by `format` query param
+// or by the Accept header, empty string means the response is json.
+func (c *Context) ExportFormat() string {
+	if f := c.QueryParam("format"); exportTypes[f] != "" {
+		return f
+	}
+
+	for _, a := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		a = strings.TrimSpace(strings.Split(a, ";")[0])
+		for f, t := range exportTypes {
+			if a == t {
+				return f
+			}
+		}
+	}
+
+	return ""
+}
+
+// Export stream all rows of the query set as the requested export format,
+// the rows are queried per Config.ExportChunkSize into the container using
+// keyset pagination, so the orders should be the fields of the model.
+// Columns are the fields query param or the json keys of the model when
+// no columns are given, values are the same as the json responses, e.g:
+//
+//	if ctx.ExportFormat() != "" {
+//		qs, _ := ctx.RequestQuery().Query(new(model.User))
+//		return ctx.Export(qs, &[]*model.User{}, cuxs.ExportColumn{Field: "name"}, cuxs.ExportColumn{Field: "group.name", Header: "Group"})
+//	}
+func (c *Context) Export(qs orm.QuerySeter, container interface{}, columns ...ExportColumn) (err error) {
+	format := c.ExportFormat()
+	if format == "" {
+		format = ExportCSV
+	}
+
+	typ := reflect.TypeOf(container).Elem().Elem()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	if len(columns) == 0 {
+		columns = exportColumns(typ, c.QueryParam("fields"))
+	}
+
+	// error of the first chunk is served as json.
+	var page *orm.CursorPage
+	if page, err = qs.Limit(Config.ExportChunkSize).AllCursor(container); err != nil {
+		return c.Serve(err)
+	}
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, exportTypes[format])
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s.%s"`, common.ToUnderscore(typ.Name()), format))
+	res.WriteHeader(http.StatusOK)
+
+	var w exportWriter
+	switch format {
+	case ExportXLSX:
+		w = newXlsxWriter(res)
+	case ExportNDJSON:
+		w = &ndjsonWriter{w: res}
+	default:
+		w = &csvWriter{w: csv.NewWriter(res)}
+	}
+
+	// the status is already written, so error of the next chunks aborts the stream
+	// and only logged, the client gets an incomplete file.
+	if err = exportStream(w, res, qs, container, page, columns); err != nil {
+		c.Log().WithError(err).Error("export is aborted")
+	}
+
+	return nil
+}
+
+// exportStream write the header, rows of the page and the next pages
+// until there are no more page.
+func exportStream(w exportWriter, res *echo.Response, qs orm.QuerySeter, container interface{}, page *orm.CursorPage, columns []ExportColumn) (err error) {
+	if err = w.header(columns); err != nil {
+		return
+	}
+
+	for {
+		if err = exportRows(w, page.Data, columns); err != nil {
+			return
+		}
+
+		if err = w.flush(); err != nil {
+			return
+		}
+		res.Flush()
+
+		if page.Next == "" {
+			break
+		}
+
+		if page, err = qs.Limit(Config.ExportChunkSize).After(page.Next).AllCursor(container); err != nil {
+			return
+		}
+	}
+
+	return w.close()
+}
+
+// exportRows write the values of the columns of each row.
+func exportRows(w exportWriter, data interface{}, columns []ExportColumn) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	var rows []interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err = d.Decode(&rows); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(columns))
+	for _, row := range rows {
+		for i, col := range columns {
+			values[i] = exportValue(row, strings.Split(col.Field, "."))
+		}
+
+		if err = w.row(values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// exportValue returns value of the nested key of the json data.
+func exportValue(v interface{}, keys []string) interface{} {
+	for _, k := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[k]
+	}
+
+	return v
+}
+
+// exportColumns returns columns of the fields param,
+// or the json keys of the struct when fields is empty.
+func exportColumns(t reflect.Type, fields string) (columns []ExportColumn) {
+	if fields != "" {
+		for _, f := range strings.Split(fields, ",") {
+			columns = append(columns, ExportColumn{Field: f})
+		}
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := tagName(f.Tag.Get("json"))
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			columns = append(columns, exportColumns(f.Type, "")...)
+			continue
+		}
+
+		if f.PkgPath != "" || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		columns = append(columns, ExportColumn{Field: name})
+	}
+
+	return
+}
+
+// exportText returns text of the value, object and array are written as json.
+func exportText(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case json.Number:
+		return x.String()
+	case bool:
+		return strconv.FormatBool(x)
+	default:
+		b, _ := json.Marshal(x)
+		return string(b)
+	}
+}
+
+// header returns header of the column.
+func (col ExportColumn) header() string {
+	if col.Header != "" {
+		return col.Header
+	}
+
+	return col.Field
+}
+
+// csvWriter export writer of csv format.
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvWriter) header(columns []ExportColumn) error {
+	h := make([]string, len(columns))
+	for i, col := range columns {
+		h[i] = col.header()
+	}
+
+	return w.w.Write(h)
+}
+
+func (w *csvWriter) row(values []interface{}) error {
+	r := make([]string, len(values))
+	for i, v := range values {
+		r[i] = exportText(v)
+
+		// text that starts like a formula is escaped, so spreadsheets are not evaluating it.
+		if _, ok := v.(string); ok && r[i] != "" && strings.ContainsRune("=+-@\t\r", rune(r[i][0])) {
+			r[i] = "'" + r[i]
+		}
+	}
+
+	return w.w.Write(r)
+}
+
+func (w *csvWriter) flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvWriter) close() error {
+	return w.flush()
+}
+
+// ndjsonWriter export writer of newline delimited json format,
+// each row is json object of the columns.
+type ndjsonWriter struct {
+	w       io.Writer
+	columns []ExportColumn
+}
+
+func (w *ndjsonWriter) header(columns []ExportColumn) error {
+	w.columns = columns
+	return nil
+}
+
+func (w *ndjsonWriter) row(values []interface{}) error {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+
+		k, _ := json.Marshal(w.columns[i].header())
+		x, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(x)
+	}
+	b.WriteString("}\n")
+
+	_, err := w.w.Write(b.Bytes())
+	return err
+}
+
+func (w *ndjsonWriter) flush() error {
+	return nil
+}
+
+func (w *ndjsonWriter) close() error {
+	return nil
+}
+
+// xlsxWriter export writer of office open xml spreadsheet with single sheet,
+// the sheet is written as the rows come and the strings are inline.
+type xlsxWriter struct {
+	z     *zip.Writer
+	sheet io.Writer
+	n     int
+}
+
+// xlsxParts static parts of the workbook.
+var xlsxParts = []struct{ name, body string }{
+	{"[Content_Types].xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`},
+	{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+	{"xl/workbook.xml", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+	{"xl/_rels/workbook.xml.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`},
+}
+
+func newXlsxWriter(w io.Writer) *xlsxWriter {
+	return &xlsxWriter{z: zip.NewWriter(w)}
+}
+
+func (w *xlsxWriter) header(columns []ExportColumn) (err error) {
+	for _, p := range xlsxParts {
+		var f io.Writer
+		if f, err = w.z.Create(p.name); err != nil {
+			return
+		}
+		if _, err = io.WriteString(f, p.body); err != nil {
+			return
+		}
+	}
+
+	if w.sheet, err = w.z.Create("xl/worksheets/sheet1.xml"); err != nil {
+		return
+	}
+
+	if _, err = io.WriteString(w.sheet, `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`); err != nil {
+		return
+	}
+
+	h := make([]interface{}, len(columns))
+	for i, col := range columns {
+		h[i] = col.header()
+	}
+
+	return w.row(h)
+}
+
+func (w *xlsxWriter) row(values []interface{}) error {
+	var b bytes.Buffer
+	w.n++
+	fmt.Fprintf(&b, `<row r="%d">`, w.n)
+	for _, v := range values {
+		if n, ok := v.(json.Number); ok {
+			fmt.Fprintf(&b, `<c t="n"><v>%s</v></c>`, n)
+			continue
+		}
+
+		b.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		xml.EscapeText(&b, []byte(exportText(v)))
+		b.WriteString(`</t></is></c>`)
+	}
+	b.WriteString(`</row>`)
+
+	_, err := w.sheet.Write(b.Bytes())
+	return err
+}
+
+func (w *xlsxWriter) flush() error {
+	return w.z.Flush()
+}
+
+func (w *xlsxWriter) close() error {
+	if _, err := io.WriteString(w.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+
+	return w.z.Close()
+}
@@ -0,0 +1,109 @@
+package irhabi
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alfatih/irhabi/orm"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextExportFormat(t *testing.T) {
+	var cases = []struct {
+		query  string
+		accept string
+		format string
+	}{
+		{"/", "", ""},
+		{"/", "application/json", ""},
+		{"/?format=csv", "", ExportCSV},
+		{"/?format=xlsx", "application/json", ExportXLSX},
+		{"/?format=pdf", "", ""},
+		{"/", "text/html, text/csv;q=0.9", ExportCSV},
+		{"/", "application/x-ndjson", ExportNDJSON},
+		{"/", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", ExportXLSX},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		ctx, _ := fakeContext(echo.GET, c.query, "", rec)
+		ctx.Request().Header.Set(echo.HeaderAccept, c.accept)
+		assert.Equal(t, c.format, ctx.ExportFormat(), c.query+" "+c.accept)
+	}
+}
+
+func TestContextExport(t *testing.T) {
+	if !assert.NoError(t, orm.RunSyncdb("default", true, false)) {
+		return
+	}
+
+	o := orm.NewOrm()
+	for _, n := range []string{"jon, snow", "arya", "sansa"} {
+		o.Insert(&resourceUser{Name: n, Email: strings.Replace(n, ", ", ".", -1) + "@qasico.com"})
+	}
+
+	defer func(n int) { Config.ExportChunkSize = n }(Config.ExportChunkSize)
+	Config.ExportChunkSize = 2
+
+	e := New()
+	(&Resource{Model: new(resourceUser)}).URLMapping(e.Group("/user"))
+	e.GET("/name", func(c echo.Context) error {
+		ctx := c.(*Context)
+		qs, _ := ctx.RequestQuery().Query(new(resourceUser))
+		return ctx.Export(qs, &[]*resourceUser{}, ExportColumn{Field: "name", Header: "Name"})
+	})
+
+	export := func(path string, accept string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(echo.GET, path, nil)
+		req.Header.Set(echo.HeaderAccept, accept)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := export("/user?format=csv&orderby=-name", "")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv", rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, `attachment; filename="resource_user.csv"`, rec.Header().Get(echo.HeaderContentDisposition))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if assert.Len(t, lines, 4) {
+		assert.Equal(t, "id,name,email", lines[0])
+		assert.True(t, strings.HasSuffix(lines[2], `,"jon, snow",jon.snow@qasico.com`), lines[2])
+		assert.True(t, strings.HasSuffix(lines[3], ",arya,arya@qasico.com"), lines[3])
+	}
+
+	rec = export("/user?fields=name&filter=name:ne:arya", "application/x-ndjson")
+	assert.Equal(t, "{\"name\":\"jon, snow\"}\n{\"name\":\"sansa\"}\n", rec.Body.String())
+
+	rec = export("/name?orderby=name", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	z, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	if assert.NoError(t, err) && assert.Len(t, z.File, 5) {
+		f, _ := z.File[4].Open()
+		b, _ := ioutil.ReadAll(f)
+		assert.Equal(t, "xl/worksheets/sheet1.xml", z.File[4].Name)
+		assert.Contains(t, string(b), `<row r="1"><c t="inlineStr"><is><t xml:space="preserve">Name</t></is></c></row>`)
+		assert.Contains(t, string(b), `<row r="4"><c t="inlineStr"><is><t xml:space="preserve">sansa</t></is></c></row></sheetData></worksheet>`)
+	}
+
+	// the orders are queried for the cursor of next chunks although not in the fields.
+	rec = export("/user?format=csv&fields=name&orderby=-name", "")
+	assert.Equal(t, "name\nsansa\n\"jon, snow\"\narya\n", rec.Body.String())
+
+	rec = export("/user?format=csv&orderby=password", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	// text that starts like a formula is escaped on csv.
+	o.Insert(&resourceUser{Name: "=HYPERLINK(\"http://x\")", Email: "-x@qasico.com"})
+	rec = export("/user?format=csv&fields=name,email&filter=name:startswith:=", "")
+	assert.Equal(t, "name,email\n\"'=HYPERLINK(\"\"http://x\"\")\",'-x@qasico.com\n", rec.Body.String())
+
+	o.Insert(&resourceUser{Name: "\t=1+1", Email: "\r=1+1@tab.com"})
+	rec = export("/user?format=csv&fields=name,email&filter=email:endswith:@tab.com", "")
+	assert.Equal(t, "name,email\n'\t=1+1,\"'\r=1+1@tab.com\"\n", rec.Body.String())
+}
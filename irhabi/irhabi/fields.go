@@ -0,0 +1,82 @@
+package irhabi
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// fieldTree tree of the requested fields,
+// nil subtree means the whole value is requested.
+type fieldTree map[string]fieldTree
+
+// newFieldTree returns tree of the fields, nested fields
+// are separated by dot e.g user.email.
+func newFieldTree(fields []string) fieldTree {
+	t := fieldTree{}
+	for _, f := range fields {
+		if f = strings.TrimSpace(f); f == "" {
+			continue
+		}
+
+		n := t
+		parts := strings.Split(f, ".")
+		for i, p := range parts {
+			sub, ok := n[p]
+			if ok && sub == nil {
+				break
+			}
+
+			if i == len(parts)-1 {
+				n[p] = nil
+				break
+			}
+
+			if !ok {
+				sub = fieldTree{}
+				n[p] = sub
+			}
+			n = sub
+		}
+	}
+
+	return t
+}
+
+// prune removes keys of the objects that not in the tree,
+// arrays are pruned on each of the elements.
+func (t fieldTree) prune(v interface{}) interface{} {
+	switch x := v.(type) {
+	case []interface{}:
+		for i := range x {
+			x[i] = t.prune(x[i])
+		}
+	case map[string]interface{}:
+		for k := range x {
+			if sub, ok := t[k]; !ok {
+				delete(x, k)
+			} else if sub != nil {
+				x[k] = sub.prune(x[k])
+			}
+		}
+	}
+
+	return v
+}
+
+// sparseFields returns json value of the data with only the requested fields.
+func sparseFields(data interface{}, fields []string) (interface{}, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err = d.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return newFieldTree(fields).prune(v), nil
+}
@@ -0,0 +1,75 @@
+package irhabi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alfatih/irhabi/orm"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+type fieldsGroup struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
+
+type fieldsUser struct {
+	ID       int64        `json:"id"`
+	Name     string       `json:"name"`
+	Email    string       `json:"email"`
+	Password string       `json:"-"`
+	Group    *fieldsGroup `json:"group"`
+}
+
+func TestContextSparseFields(t *testing.T) {
+	users := []*fieldsUser{
+		{ID: 1, Name: "alif", Email: "alif@qasico.com", Group: &fieldsGroup{ID: 10, Name: "admin"}},
+		{ID: 2, Name: "amri", Email: "amri@qasico.com"},
+	}
+
+	var cases = []struct {
+		query    string
+		expected string
+	}{
+		{"fields=id,name", `[{"id":1,"name":"alif"},{"id":2,"name":"amri"}]`},
+		{"fields=id,group.name", `[{"id":1,"group":{"name":"admin"}},{"id":2,"group":null}]`},
+		{"fields=group.name,group", `[{"group":{"id":10,"name":"admin"}},{"group":null}]`},
+		{"fields=email,unknown", `[{"email":"alif@qasico.com"},{"email":"amri@qasico.com"}]`},
+		{"", `[{"id":1,"name":"alif","email":"alif@qasico.com","group":{"id":10,"name":"admin"}},{"id":2,"name":"amri","email":"amri@qasico.com","group":null}]`},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		ctx, _ := fakeContext(echo.GET, "/users?"+c.query, "", rec)
+		ctx.Data(users, 2)
+
+		if assert.NoError(t, ctx.Serve(nil)) {
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.JSONEq(t, `{"status":"success","data":`+c.expected+`,"total":2}`, rec.Body.String(), c.query)
+		}
+	}
+}
+
+func TestContextSparseFieldsError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ctx, _ := fakeContext(echo.GET, "/users?fields=id,password", "", rec)
+
+	err := &orm.FieldsError{Errors: map[string]string{"fields.password": "The password field is not exposed."}}
+	if assert.NoError(t, ctx.Serve(err)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.JSONEq(t, `{"status":"fail","message":"Unprocessable Entity","errors":{"fields.password":"The password field is not exposed."}}`, rec.Body.String())
+	}
+}
+
+func TestContextSparseFieldsMarshalError(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ctx, _ := fakeContext(echo.GET, "/users?fields=id", "", rec)
+	ctx.Data(map[string]interface{}{"id": 1, "fn": func() {}})
+
+	if assert.NoError(t, ctx.Serve(nil)) {
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.JSONEq(t, `{"status":"fail","message":"Unprocessable Entity","errors":{"fields":"json: unsupported type: func()"}}`, rec.Body.String())
+	}
+}
@@ -0,0 +1,196 @@
+package irhabi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alfatih/irhabi/orm"
+	"github.com/labstack/echo"
+)
+
+// Status of the health checks.
+const (
+	HealthOK           = "ok"
+	HealthFail         = "fail"
+	HealthShuttingDown = "shutting_down"
+)
+
+// errHealthTimeout error of the check that not finished within Config.HealthTimeout.
+var errHealthTimeout = errors.New("health check timeout")
+
+type (
+	// HealthChecker check of the application dependency,
+	// the same as beego/toolbox HealthChecker.
+	HealthChecker interface {
+		Check() error
+	}
+
+	// HealthCheckFunc function that implementing HealthChecker.
+	HealthCheckFunc func() error
+
+	// HealthReport response of the health endpoints.
+	HealthReport struct {
+		Status string                  `json:"status"`
+		Checks map[string]*HealthCheck `json:"checks,omitempty"`
+	}
+
+	// HealthCheck result of single check.
+	HealthCheck struct {
+		Status  string  `json:"status"`
+		Latency float64 `json:"latency_ms"`
+		Error   string  `json:"error,omitempty"`
+	}
+
+	// healthRegistry registered checks and the cached report.
+	healthRegistry struct {
+		mu       sync.Mutex
+		checks   map[string]HealthChecker
+		report   *HealthReport
+		cachedAt time.Time
+	}
+)
+
+var (
+	healths = &healthRegistry{checks: make(map[string]HealthChecker)}
+
+	// shuttingDown marking the server is shutting down, so it is not ready.
+	shuttingDown int32
+)
+
+// Check calling the function.
+func (f HealthCheckFunc) Check() error {
+	return f()
+}
+
+// AddHealthCheck register check of the dependency that run by /healthz and /readyz,
+// ping of all orm database aliases are registered by default, e.g:
+//
+//	cuxs.AddHealthCheck("mailer", cuxs.HealthCheckFunc(mailer.Ping))
+//	cuxs.AddHealthCheck("docv", cuxs.HealthCheckFunc(docv.Ping))
+func AddHealthCheck(name string, hc HealthChecker) {
+	healths.mu.Lock()
+	defer healths.mu.Unlock()
+
+	healths.checks[name] = hc
+	healths.report = nil
+}
+
+// Livez handler of liveness probe, it is always ok while the process is serving.
+func Livez(c echo.Context) error {
+	return c.JSON(http.StatusOK, &HealthReport{Status: HealthOK})
+}
+
+// Healthz handler of health probe, it returns 503 when one of the checks is failing.
+func Healthz(c echo.Context) error {
+	r := healths.run()
+	if r.Status != HealthOK {
+		return c.JSON(http.StatusServiceUnavailable, r)
+	}
+
+	return c.JSON(http.StatusOK, r)
+}
+
+// Readyz handler of readiness probe, it is the same as Healthz
+// but failing as soon as the server is shutting down.
+func Readyz(c echo.Context) error {
+	if atomic.LoadInt32(&shuttingDown) == 1 {
+		return c.JSON(http.StatusServiceUnavailable, &HealthReport{Status: HealthShuttingDown})
+	}
+
+	return Healthz(c)
+}
+
+// run returns the cached report, or run all checks concurrently
+// when the cache is older than Config.HealthCacheTTL.
+func (h *healthRegistry) run() *HealthReport {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.report != nil && time.Since(h.cachedAt) < Config.HealthCacheTTL {
+		return h.report
+	}
+
+	checks := make(map[string]HealthChecker, len(h.checks))
+	for _, alias := range orm.DataBaseAliases() {
+		checks["database:"+alias] = dbHealthCheck(alias)
+	}
+	for name, hc := range h.checks {
+		checks[name] = hc
+	}
+
+	names := make([]string, 0, len(checks))
+	for name := range checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	r := &HealthReport{Status: HealthOK, Checks: make(map[string]*HealthCheck, len(checks))}
+	results := make([]*HealthCheck, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, hc HealthChecker) {
+			defer wg.Done()
+			results[i] = runHealthCheck(hc)
+		}(i, checks[name])
+	}
+	wg.Wait()
+
+	for i, name := range names {
+		r.Checks[name] = results[i]
+		if results[i].Status != HealthOK {
+			r.Status = HealthFail
+		}
+	}
+
+	h.report, h.cachedAt = r, time.Now()
+
+	return r
+}
+
+// runHealthCheck run the check within Config.HealthTimeout,
+// error message is only exposed on debug mode.
+func runHealthCheck(hc HealthChecker) *HealthCheck {
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- hc.Check()
+	}()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-time.After(Config.HealthTimeout):
+		err = errHealthTimeout
+	}
+
+	r := &HealthCheck{Status: HealthOK, Latency: float64(time.Since(start)) / float64(time.Millisecond)}
+	if err != nil {
+		r.Status = HealthFail
+		if IsDebug() || err == errHealthTimeout {
+			r.Error = err.Error()
+		}
+	}
+
+	return r
+}
+
+// dbHealthCheck returns check that ping the database of orm alias.
+func dbHealthCheck(alias string) HealthCheckFunc {
+	return func() error {
+		db, err := orm.GetDB(alias)
+		if err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), Config.HealthTimeout)
+		defer cancel()
+
+		return db.PingContext(ctx)
+	}
+}
@@ -0,0 +1,83 @@
+package irhabi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func healthRequest(e *echo.Echo, path string) (int, *HealthReport) {
+	req, _ := http.NewRequest(echo.GET, path, nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	r := new(HealthReport)
+	json.Unmarshal(rec.Body.Bytes(), r)
+
+	return rec.Code, r
+}
+
+func TestHealth(t *testing.T) {
+	defer func(d time.Duration) { Config.HealthTimeout = d }(Config.HealthTimeout)
+	defer func() { healths.checks = make(map[string]HealthChecker) }()
+	defer func(v bool) { Config.HealthRoutes = v }(Config.HealthRoutes)
+	Config.HealthTimeout = 50 * time.Millisecond
+	Config.HealthRoutes = true
+
+	e := New()
+	var calls int32
+	AddHealthCheck("mailer", HealthCheckFunc(func() error {
+		atomic.AddInt32(&calls, 1)
+		return nil
+	}))
+
+	code, r := healthRequest(e, "/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthOK, r.Status)
+	if assert.Len(t, r.Checks, 2) {
+		assert.Equal(t, HealthOK, r.Checks["database:default"].Status)
+		assert.Equal(t, HealthOK, r.Checks["mailer"].Status)
+	}
+
+	// the result is cached.
+	code, _ = healthRequest(e, "/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	AddHealthCheck("docv", HealthCheckFunc(func() error {
+		return errors.New("no reachable servers")
+	}))
+	AddHealthCheck("notify", HealthCheckFunc(func() error {
+		time.Sleep(time.Second)
+		return nil
+	}))
+
+	code, r = healthRequest(e, "/healthz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthFail, r.Status)
+	if assert.Len(t, r.Checks, 4) {
+		assert.Equal(t, HealthOK, r.Checks["mailer"].Status)
+		assert.Equal(t, HealthFail, r.Checks["docv"].Status)
+		assert.Equal(t, HealthFail, r.Checks["notify"].Status)
+		assert.Equal(t, "health check timeout", r.Checks["notify"].Error)
+		assert.True(t, r.Checks["notify"].Latency >= 50)
+	}
+
+	atomic.StoreInt32(&shuttingDown, 1)
+	defer atomic.StoreInt32(&shuttingDown, 0)
+
+	code, r = healthRequest(e, "/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, HealthShuttingDown, r.Status)
+
+	code, r = healthRequest(e, "/livez")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, HealthOK, r.Status)
+}
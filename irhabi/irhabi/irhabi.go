@@ -1,14 +1,23 @@
 package irhabi
 
 import (
+	"sync"
+
+	"github.com/alfatih/irhabi/common"
 	"github.com/alfatih/irhabi/common/log"
 	"github.com/alfatih/irhabi/irhabi/mw"
+	"github.com/alfatih/irhabi/orm"
 	"github.com/labstack/echo"
 	"github.com/labstack/echo/middleware"
 )
 
-// New creates an instance of Echo.
-func New() *echo.Echo {
+// New creates an instance of Echo, the middlewares is registered
+// after custom context injection, if none is given DefaultMiddlewares will be used.
+// So the middleware stack can be replaced or reordered e.g:
+//
+//	m := irhabi.DefaultMiddlewares()
+//	e := irhabi.New(append([]echo.MiddlewareFunc{myMiddleware}, m...)...)
+func New(middlewares ...echo.MiddlewareFunc) *echo.Echo {
 	// Make new instaces echo
 	e := echo.New()
 
@@ -25,21 +34,100 @@ func New() *echo.Echo {
 	e.HTTPErrorHandler = HTTPErrorHandler
 
 	// registering middleware
-	e.Use(mw.HTTPLogger())
-	e.Use(middleware.Gzip(), middleware.CORS())
-	e.Use(middleware.Secure(), middleware.Recover())
+	if len(middlewares) == 0 {
+		middlewares = DefaultMiddlewares()
+	}
+	e.Use(middlewares...)
+
+	if Config.MetricsRoute != "" {
+		DefaultMetrics.ObserveORM()
+		e.GET(Config.MetricsRoute, DefaultMetrics.Handler())
+	}
+
+	if Config.HealthRoutes {
+		e.GET("/livez", Livez)
+		e.GET("/healthz", Healthz)
+		e.GET("/readyz", Readyz)
+	}
 
 	return e
 }
 
+// idKeyWarning warns the random key of obfuscated ids only once.
+var idKeyWarning sync.Once
+
+// Setup applies the config values that shared by the process into orm and common packages,
+// the legacy conditions, the cursor secret and the key of obfuscated ids.
+// It is called by DbSetup and StartServer, New is not changing them.
+func Setup() {
+	orm.LegacyConditions = Config.LegacyConditions
+	if Config.CursorSecret != "" {
+		orm.CursorSecret = []byte(Config.CursorSecret)
+	}
+	if Config.IDKey != "" {
+		common.SetIDKey(Config.IDKey)
+	} else {
+		idKeyWarning.Do(func() {
+			log.Warning("APP_ID_KEY is not set, obfuscated ids are using random key and not valid after restart")
+		})
+	}
+}
+
+// DefaultMiddlewares returns middleware stack configured by application config,
+// request id, http logger, metrics, cors, secure headers, body limit, gzip, request timeout and recover.
+func DefaultMiddlewares() (m []echo.MiddlewareFunc) {
+	m = append(m, mw.RequestID(), mw.HTTPLogger())
+
+	if Config.MetricsRoute != "" {
+		m = append(m, DefaultMetrics.Middleware())
+	}
+
+	m = append(m, middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOrigins: Config.CorsOrigins,
+		AllowMethods: Config.CorsMethods,
+		AllowHeaders: Config.CorsHeaders,
+	}))
+
+	m = append(m, middleware.SecureWithConfig(middleware.SecureConfig{
+		XSSProtection:         Config.SecureXSS,
+		ContentTypeNosniff:    Config.SecureNosniff,
+		XFrameOptions:         Config.SecureXFrame,
+		HSTSMaxAge:            Config.SecureHSTSMaxAge,
+		ContentSecurityPolicy: Config.SecureCSP,
+	}))
+
+	if Config.BodyLimit != "" {
+		m = append(m, middleware.BodyLimit(Config.BodyLimit))
+	}
+
+	if Config.GzipEnable {
+		m = append(m, middleware.GzipWithConfig(middleware.GzipConfig{
+			Level: Config.GzipLevel,
+		}))
+	}
+
+	if Config.RequestTimeout > 0 {
+		m = append(m, mw.Timeout(Config.RequestTimeout))
+	}
+
+	m = append(m, middleware.Recover())
+
+	return m
+}
+
 // StartServer starting echo servers
+// and gracefully shutting down on SIGINT or SIGTERM.
 func StartServer(e *echo.Echo) {
+	// jwt keys are checked before serving, misconfigured server is never started.
+	Setup()
+	Jwt()
+
 	if IsDebug() {
 		listRoutes(e)
 	}
 
 	// starting webserver
-	if err := e.Start(Config.Host); err != nil {
+	if err := Serve(e); err != nil {
 		log.Error(err)
 	}
 }
@@ -3,8 +3,12 @@ package irhabi
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/alfatih/irhabi/common"
+	"github.com/alfatih/irhabi/irhabi/mw"
+	"github.com/alfatih/irhabi/orm"
 	"github.com/labstack/echo"
 	"github.com/stretchr/testify/assert"
 )
@@ -61,9 +65,113 @@ func TestEchoRoutes(t *testing.T) {
 	}
 }
 
+func TestDefaultMiddlewares(t *testing.T) {
+	c := *Config
+	defer func() {
+		*Config = c
+	}()
+
+	Config.GzipEnable = true
+	Config.CorsOrigins = []string{"http://a.com"}
+	Config.BodyLimit = "1K"
+
+	e := New()
+	e.POST("/ok", func(c echo.Context) error {
+		return c.String(http.StatusOK, "OK")
+	})
+
+	var cases = []struct {
+		origin   string
+		body     string
+		code     int
+		encoding string
+		cors     string
+	}{
+		{"http://a.com", "", http.StatusOK, "gzip", "http://a.com"},
+		{"http://b.com", "", http.StatusOK, "gzip", ""},
+		{"http://a.com", strings.Repeat("x", 2048), http.StatusRequestEntityTooLarge, "", "http://a.com"},
+	}
+
+	for _, x := range cases {
+		req, _ := http.NewRequest(echo.POST, "/ok", strings.NewReader(x.body))
+		req.Header.Set(echo.HeaderAcceptEncoding, "gzip")
+		req.Header.Set(echo.HeaderOrigin, x.origin)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+
+		assert.Equal(t, x.code, rec.Code)
+		assert.Equal(t, x.cors, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+		if x.encoding != "" {
+			assert.Equal(t, x.encoding, rec.Header().Get(echo.HeaderContentEncoding))
+		}
+		assert.Equal(t, "nosniff", rec.Header().Get(echo.HeaderXContentTypeOptions))
+		assert.NotEmpty(t, rec.Header().Get(mw.HeaderRequestID))
+	}
+}
+
+func TestNewWithMiddlewares(t *testing.T) {
+	var called []string
+	e := New(func(n echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			called = append(called, "custom")
+			return n(c)
+		}
+	})
+
+	e.GET("/ok", func(c echo.Context) error {
+		_, ok := c.(*Context)
+		assert.True(t, ok)
+		return c.String(http.StatusOK, "OK")
+	})
+
+	code, _ := request(echo.GET, "/ok", e)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{"custom"}, called)
+
+	code, b := request(echo.GET, "/404", e)
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Contains(t, b, `"status":"fail"`)
+}
+
 func request(method, path string, e *echo.Echo) (int, string) {
 	req, _ := http.NewRequest(method, path, nil)
 	rec := httptest.NewRecorder()
 	e.ServeHTTP(rec, req)
 	return rec.Code, rec.Body.String()
 }
+
+func TestSetup(t *testing.T) {
+	defer func(c config, l bool, s []byte) {
+		*Config = c
+		orm.LegacyConditions = l
+		orm.CursorSecret = s
+	}(*Config, orm.LegacyConditions, orm.CursorSecret)
+
+	// the random keys are kept when they are not configured.
+	secret, id := orm.CursorSecret, common.EncodeID(1)
+	Config.CursorSecret = ""
+	Config.IDKey = ""
+	Setup()
+	assert.Equal(t, secret, orm.CursorSecret)
+	assert.Equal(t, id, common.EncodeID(1))
+
+	Config.LegacyConditions = false
+	Config.CursorSecret = "s3cr3t"
+
+	// creating echo instances is not changing the process values.
+	New()
+	assert.True(t, orm.LegacyConditions)
+	assert.NotEqual(t, []byte("s3cr3t"), orm.CursorSecret)
+
+	Config.IDKey = "k3y"
+	Setup()
+	assert.False(t, orm.LegacyConditions)
+	assert.Equal(t, []byte("s3cr3t"), orm.CursorSecret)
+	assert.NotEqual(t, id, common.EncodeID(1))
+
+	// database setup applies them before registering the databases.
+	Config.LegacyConditions = true
+	Config.DbEngine = "mongodb"
+	assert.Error(t, DbSetup())
+	assert.True(t, orm.LegacyConditions)
+}
@@ -0,0 +1,555 @@
+package irhabi
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfatih/irhabi/common/log"
+	"github.com/alfatih/irhabi/orm"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+)
+
+const (
+	// JwtTypeAccess type claim of access token.
+	JwtTypeAccess = "access"
+
+	// JwtTypeRefresh type claim of refresh token.
+	JwtTypeRefresh = "refresh"
+)
+
+var (
+	// ErrJwtMissing error when jwt token is not found in request header.
+	ErrJwtMissing = echo.NewHTTPError(http.StatusBadRequest, "missing or malformed jwt")
+
+	// ErrJwtInvalid error when jwt token cannot be verified or already expired.
+	ErrJwtInvalid = echo.NewHTTPError(http.StatusUnauthorized, "invalid or expired jwt")
+
+	// ErrJwtRevoked error when jwt token has been revoked.
+	ErrJwtRevoked = echo.NewHTTPError(http.StatusUnauthorized, "revoked jwt")
+)
+
+type (
+	// JwtConfig configuration for creating jwt manager.
+	JwtConfig struct {
+		Algorithm       string        // Signing algorithm, HS256, RS256 or ES256
+		Secret          string        // Shared secret for HMAC algorithms
+		KeyID           string        // Key id written into kid header
+		PrivateKeyFile  string        // PEM private key file for RSA and ECDSA algorithms
+		PublicKeyFile   string        // PEM public key file for RSA and ECDSA algorithms
+		Issuer          string        // Issuer claim of generated tokens
+		Lifetime        time.Duration // Life time of access token, default is 72 hours
+		RefreshLifetime time.Duration // Life time of refresh token, default is 30 days
+		Revoker         JwtRevoker    // Revocation list, default is in memory
+	}
+
+	// JwtClaims standard claims issued by jwt manager,
+	// custom typed claims should embed this struct.
+	JwtClaims struct {
+		jwt.StandardClaims
+		Type string `json:"typ,omitempty"`
+	}
+
+	// Claims is typed claims that embedding JwtClaims.
+	Claims interface {
+		jwt.Claims
+		standard() *JwtClaims
+	}
+
+	// JwtPair pair of access token and refresh token.
+	JwtPair struct {
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		TokenType    string `json:"token_type"`
+		ExpiresIn    int64  `json:"expires_in"`
+	}
+
+	// JwtManager issuing, verifying and revoking json web token
+	// with multiple keys identified by kid header.
+	JwtManager struct {
+		mu              sync.RWMutex
+		keys            map[string]*jwtKey
+		active          string
+		issuer          string
+		lifetime        time.Duration
+		refreshLifetime time.Duration
+		revoker         JwtRevoker
+	}
+
+	// jwtKey signing and verification key.
+	jwtKey struct {
+		method jwt.SigningMethod
+		sign   interface{}
+		verify interface{}
+	}
+)
+
+// standard returns the standard claims, makes it compatible with `Claims` interface.
+func (c *JwtClaims) standard() *JwtClaims {
+	return c
+}
+
+// NewJwtManager creates jwt manager from the config,
+// key is loaded from the key files if provided, otherwise using the secret.
+func NewJwtManager(c JwtConfig) (m *JwtManager, err error) {
+	m = &JwtManager{
+		keys:            make(map[string]*jwtKey),
+		issuer:          c.Issuer,
+		lifetime:        c.Lifetime,
+		refreshLifetime: c.RefreshLifetime,
+		revoker:         c.Revoker,
+	}
+
+	if m.lifetime <= 0 {
+		m.lifetime = time.Hour * 72
+	}
+
+	if m.refreshLifetime <= 0 {
+		m.refreshLifetime = time.Hour * 24 * 30
+	}
+
+	if m.revoker == nil {
+		m.revoker = NewMemoryRevoker()
+	}
+
+	if c.Algorithm == "" {
+		c.Algorithm = jwt.SigningMethodHS256.Alg()
+	}
+
+	if c.PrivateKeyFile != "" || c.PublicKeyFile != "" {
+		err = m.LoadKeyFiles(c.KeyID, c.Algorithm, c.PrivateKeyFile, c.PublicKeyFile)
+	} else if c.Secret != "" {
+		err = m.AddKey(c.KeyID, c.Algorithm, []byte(c.Secret), []byte(c.Secret))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// AddKey register signing and verification key with the kid,
+// sign key can be nil for the key that only used for verification.
+// The first key registered will be used as the signing key.
+func (m *JwtManager) AddKey(kid string, alg string, sign interface{}, verify interface{}) error {
+	method := jwt.GetSigningMethod(alg)
+	if method == nil {
+		return fmt.Errorf("jwt: unsupported signing algorithm %s", alg)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.keys[kid] = &jwtKey{method: method, sign: sign, verify: verify}
+	if _, ok := m.keys[m.active]; !ok || m.keys[m.active].sign == nil {
+		m.active = kid
+	}
+
+	return nil
+}
+
+// LoadKeyFiles register RSA or ECDSA key pair from PEM files,
+// private key file can be empty for the key that only used for verification,
+// and public key file can be empty if it can be derived from the private key.
+func (m *JwtManager) LoadKeyFiles(kid string, alg string, privateFile string, publicFile string) (err error) {
+	var sign, verify interface{}
+	var b []byte
+
+	if privateFile != "" {
+		if b, err = ioutil.ReadFile(privateFile); err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(alg, "ES") {
+			var k *ecdsa.PrivateKey
+			if k, err = jwt.ParseECPrivateKeyFromPEM(b); err == nil {
+				sign, verify = k, &k.PublicKey
+			}
+		} else {
+			var k *rsa.PrivateKey
+			if k, err = jwt.ParseRSAPrivateKeyFromPEM(b); err == nil {
+				sign, verify = k, &k.PublicKey
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	if publicFile != "" {
+		if b, err = ioutil.ReadFile(publicFile); err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(alg, "ES") {
+			verify, err = jwt.ParseECPublicKeyFromPEM(b)
+		} else {
+			verify, err = jwt.ParseRSAPublicKeyFromPEM(b)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return m.AddKey(kid, alg, sign, verify)
+}
+
+// UseKey set the key that will be used for signing new tokens,
+// old keys are still can be used to verify the tokens they signed.
+func (m *JwtManager) UseKey(kid string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if k, ok := m.keys[kid]; !ok || k.sign == nil {
+		return fmt.Errorf("jwt: signing key %s is not registered", kid)
+	}
+
+	m.active = kid
+	return nil
+}
+
+// RemoveKey unregister the key, tokens signed with this key will become invalid.
+func (m *JwtManager) RemoveKey(kid string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.keys, kid)
+}
+
+// Sign generate signed access token from the claims,
+// expires, issued at, id and type claims are filled when empty.
+func (m *JwtManager) Sign(claims Claims) (string, error) {
+	m.prepare(claims.standard(), JwtTypeAccess, m.lifetime)
+
+	return m.sign(claims)
+}
+
+// Issue generate pair of access token and refresh token from the claims.
+func (m *JwtManager) Issue(claims Claims) (p *JwtPair, err error) {
+	sc := claims.standard()
+	p = &JwtPair{TokenType: "Bearer", ExpiresIn: int64(m.lifetime / time.Second)}
+
+	m.prepare(sc, JwtTypeAccess, m.lifetime)
+	if p.AccessToken, err = m.sign(claims); err != nil {
+		return nil, err
+	}
+
+	sc.ExpiresAt, sc.Id, sc.Type = 0, "", ""
+	m.prepare(sc, JwtTypeRefresh, m.refreshLifetime)
+	if p.RefreshToken, err = m.sign(claims); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// Refresh exchange refresh token with the new pair of tokens,
+// the claims is decoded from refresh token and the old one will be revoked.
+func (m *JwtManager) Refresh(token string, claims Claims) (p *JwtPair, err error) {
+	if _, err = m.Parse(token, claims); err != nil {
+		return nil, err
+	}
+
+	sc := claims.standard()
+	if sc.Type != JwtTypeRefresh {
+		return nil, ErrJwtInvalid
+	}
+
+	if err = m.revoker.Revoke(sc.Id, time.Unix(sc.ExpiresAt, 0)); err != nil {
+		return nil, err
+	}
+
+	sc.ExpiresAt, sc.IssuedAt, sc.Id, sc.Type = 0, 0, "", ""
+	return m.Issue(claims)
+}
+
+// Parse verify the token and decode the claims,
+// will return error if the token is invalid or has been revoked.
+func (m *JwtManager) Parse(token string, claims jwt.Claims) (t *jwt.Token, err error) {
+	if t, err = jwt.ParseWithClaims(token, claims, m.keyFunc); err != nil || !t.Valid {
+		return nil, ErrJwtInvalid
+	}
+
+	var id string
+	switch c := claims.(type) {
+	case Claims:
+		id = c.standard().Id
+	case jwt.MapClaims:
+		id, _ = c["jti"].(string)
+	}
+
+	if id != "" {
+		var revoked bool
+		if revoked, err = m.revoker.IsRevoked(id); err != nil {
+			return nil, err
+		} else if revoked {
+			return nil, ErrJwtRevoked
+		}
+	}
+
+	return t, nil
+}
+
+// Revoke put the token into revocation list until it expired.
+func (m *JwtManager) Revoke(token string) error {
+	c := jwt.MapClaims{}
+	if _, err := m.Parse(token, c); err != nil {
+		return err
+	}
+
+	id, _ := c["jti"].(string)
+	if id == "" {
+		return ErrJwtInvalid
+	}
+
+	exp, _ := c["exp"].(float64)
+	return m.revoker.Revoke(id, time.Unix(int64(exp), 0))
+}
+
+// Middleware returns a JSON Web Token (JWT) auth middleware.
+//
+// For valid access token, it sets the token in context as `user` and calls next handler.
+// For invalid, expired or revoked token, it returns "401 - Unauthorized" error.
+// For empty token, it returns "400 - Bad Request" error.
+func (m *JwtManager) Middleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			auth := c.Request().Header.Get(echo.HeaderAuthorization)
+			if len(auth) <= 7 || !strings.EqualFold(auth[:7], "Bearer ") {
+				return ErrJwtMissing
+			}
+
+			claims := jwt.MapClaims{}
+			t, err := m.Parse(auth[7:], claims)
+			if err != nil {
+				return err
+			}
+
+			if typ, _ := claims["typ"].(string); typ == JwtTypeRefresh {
+				return ErrJwtInvalid
+			}
+
+			c.Set("user", t)
+			return next(c)
+		}
+	}
+}
+
+// prepare fill the empty standard claims.
+func (m *JwtManager) prepare(c *JwtClaims, typ string, lifetime time.Duration) {
+	now := time.Now()
+	if c.IssuedAt == 0 {
+		c.IssuedAt = now.Unix()
+	}
+
+	if c.ExpiresAt == 0 {
+		c.ExpiresAt = now.Add(lifetime).Unix()
+	}
+
+	if c.Id == "" {
+		c.Id = jwtID()
+	}
+
+	if c.Issuer == "" {
+		c.Issuer = m.issuer
+	}
+
+	if c.Type == "" {
+		c.Type = typ
+	}
+}
+
+// sign signing the claims using active key.
+func (m *JwtManager) sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	kid := m.active
+	k, ok := m.keys[kid]
+	m.mu.RUnlock()
+
+	if !ok || k.sign == nil {
+		return "", fmt.Errorf("jwt: no signing key available")
+	}
+
+	t := jwt.NewWithClaims(k.method, claims)
+	if kid != "" {
+		t.Header["kid"] = kid
+	}
+
+	return t.SignedString(k.sign)
+}
+
+// keyFunc lookup verification key by the kid header.
+func (m *JwtManager) keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+
+	m.mu.RLock()
+	k, ok := m.keys[kid]
+	m.mu.RUnlock()
+
+	if !ok || k.verify == nil {
+		return nil, fmt.Errorf("jwt: unknown key id %s", kid)
+	}
+
+	if t.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("jwt: unexpected signing method %s", t.Method.Alg())
+	}
+
+	return k.verify, nil
+}
+
+// jwtID generate random unique id of token.
+func jwtID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
+
+// JwtRevoker storage of revoked token ids.
+type JwtRevoker interface {
+	Revoke(id string, expiresAt time.Time) error
+	IsRevoked(id string) (bool, error)
+}
+
+// memoryRevoker store revoked token in memory.
+type memoryRevoker struct {
+	mu  sync.Mutex
+	ids map[string]time.Time
+}
+
+// NewMemoryRevoker creates in memory revocation list,
+// the list is not shared with other instances of application.
+func NewMemoryRevoker() JwtRevoker {
+	return &memoryRevoker{ids: make(map[string]time.Time)}
+}
+
+// Revoke put the token id into revocation list and
+// cleaning up the expired token ids.
+func (r *memoryRevoker) Revoke(id string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for k, v := range r.ids {
+		if v.Before(now) {
+			delete(r.ids, k)
+		}
+	}
+
+	r.ids[id] = expiresAt
+	return nil
+}
+
+// IsRevoked check is the token id has been revoked.
+func (r *memoryRevoker) IsRevoked(id string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.ids[id]
+	return ok, nil
+}
+
+// dbRevoker store revoked token in the database.
+type dbRevoker struct {
+	alias string
+	table string
+}
+
+// NewDbRevoker creates revocation list stored in database table
+// using the orm alias, the table should be created with the structure:
+//
+//	CREATE TABLE jwt_revocation (
+//		jti VARCHAR(64) NOT NULL PRIMARY KEY,
+//		expires_at DATETIME NOT NULL
+//	);
+func NewDbRevoker(alias string, table string) JwtRevoker {
+	return &dbRevoker{alias: alias, table: table}
+}
+
+// Revoke put the token id into revocation table and
+// cleaning up the expired token ids.
+func (r *dbRevoker) Revoke(id string, expiresAt time.Time) (err error) {
+	o := orm.NewOrm()
+	if err = o.Using(r.alias); err != nil {
+		return err
+	}
+
+	if _, err = o.Raw(fmt.Sprintf("DELETE FROM %s WHERE expires_at < ?", r.table), time.Now()).Exec(); err != nil {
+		return err
+	}
+
+	_, err = o.Raw(fmt.Sprintf("INSERT INTO %s (jti, expires_at) VALUES (?, ?)", r.table), id, expiresAt).Exec()
+	return err
+}
+
+// IsRevoked check is the token id exists in revocation table.
+func (r *dbRevoker) IsRevoked(id string) (bool, error) {
+	o := orm.NewOrm()
+	if err := o.Using(r.alias); err != nil {
+		return false, err
+	}
+
+	var total int64
+	if err := o.Raw(fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE jti = ?", r.table), id).QueryRow(&total); err != nil {
+		return false, err
+	}
+
+	return total > 0, nil
+}
+
+var (
+	jwtManager *JwtManager
+	jwtOnce    sync.Once
+)
+
+// Jwt returns default jwt manager that created from application config,
+// it panics when neither secret nor key files are configured outside debug mode,
+// on debug mode the tokens are signed by random secret.
+func Jwt() *JwtManager {
+	jwtOnce.Do(func() {
+		if jwtManager != nil {
+			return
+		}
+
+		if Config.JwtSecret == "" && Config.JwtPrivateKey == "" && Config.JwtPublicKey == "" {
+			if !IsDebug() {
+				panic(fmt.Errorf("jwt: APP_JWT_SECRET or APP_JWT_PRIVATE_KEY is required"))
+			}
+
+			Config.JwtSecret = jwtID() + jwtID()
+			log.Warning("APP_JWT_SECRET is not set, tokens are signed by random secret and not valid after restart")
+		}
+
+		var err error
+		if jwtManager, err = NewJwtManager(JwtConfig{
+			Algorithm:       Config.JwtAlgorithm,
+			Secret:          Config.JwtSecret,
+			KeyID:           Config.JwtKeyID,
+			PrivateKeyFile:  Config.JwtPrivateKey,
+			PublicKeyFile:   Config.JwtPublicKey,
+			Lifetime:        Config.JwtLifetime,
+			RefreshLifetime: Config.JwtRefreshLifetime,
+		}); err != nil {
+			panic(err)
+		}
+	})
+
+	return jwtManager
+}
+
+// SetJwt replace default jwt manager.
+func SetJwt(m *JwtManager) {
+	jwtOnce.Do(func() {})
+	jwtManager = m
+}
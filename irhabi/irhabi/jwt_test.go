@@ -0,0 +1,205 @@
+package irhabi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+type testClaims struct {
+	JwtClaims
+	UserID int64  `json:"id"`
+	Role   string `json:"role"`
+}
+
+func writeKeyFiles(t *testing.T, dir string, name string, alg string) (string, string) {
+	var priv, pub []byte
+	var err error
+
+	if alg == "ES256" {
+		k, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		b, _ := x509.MarshalECPrivateKey(k)
+		priv = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: b})
+		b, _ = x509.MarshalPKIXPublicKey(&k.PublicKey)
+		pub = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: b})
+	} else {
+		k, _ := rsa.GenerateKey(rand.Reader, 1024)
+		priv = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(k)})
+		b, _ := x509.MarshalPKIXPublicKey(&k.PublicKey)
+		pub = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: b})
+	}
+
+	pf := filepath.Join(dir, name+".pem")
+	uf := filepath.Join(dir, name+".pub")
+	if err = ioutil.WriteFile(pf, priv, 0600); err == nil {
+		err = ioutil.WriteFile(uf, pub, 0600)
+	}
+	assert.NoError(t, err)
+
+	return pf, uf
+}
+
+func TestJwtManagerAsymmetric(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "jwt")
+	defer os.RemoveAll(dir)
+
+	for _, alg := range []string{"RS256", "ES256"} {
+		pf, uf := writeKeyFiles(t, dir, alg, alg)
+		m, err := NewJwtManager(JwtConfig{Algorithm: alg, KeyID: "k1", PrivateKeyFile: pf, PublicKeyFile: uf})
+		if !assert.NoError(t, err) {
+			continue
+		}
+
+		token, err := m.Sign(&testClaims{UserID: 1, Role: "admin"})
+		assert.NoError(t, err)
+
+		c := &testClaims{}
+		if _, err = m.Parse(token, c); assert.NoError(t, err) {
+			assert.Equal(t, int64(1), c.UserID)
+			assert.Equal(t, "admin", c.Role)
+			assert.Equal(t, JwtTypeAccess, c.Type)
+		}
+
+		// verify only key cannot be used for signing
+		v, _ := NewJwtManager(JwtConfig{Algorithm: alg, KeyID: "k1", PublicKeyFile: uf})
+		_, err = v.Parse(token, &testClaims{})
+		assert.NoError(t, err)
+		_, err = v.Sign(&testClaims{})
+		assert.Error(t, err)
+	}
+}
+
+func TestJwtManagerKeyRotation(t *testing.T) {
+	m, _ := NewJwtManager(JwtConfig{Secret: "old", KeyID: "old"})
+	old, _ := m.Sign(&testClaims{UserID: 1})
+
+	assert.NoError(t, m.AddKey("new", "HS512", []byte("new"), []byte("new")))
+	assert.NoError(t, m.UseKey("new"))
+	assert.Error(t, m.UseKey("unknown"))
+
+	current, _ := m.Sign(&testClaims{UserID: 2})
+	_, err := m.Parse(old, &testClaims{})
+	assert.NoError(t, err)
+	_, err = m.Parse(current, &testClaims{})
+	assert.NoError(t, err)
+
+	m.RemoveKey("old")
+	_, err = m.Parse(old, &testClaims{})
+	assert.Equal(t, ErrJwtInvalid, err)
+}
+
+func TestJwtManagerLifetime(t *testing.T) {
+	m, _ := NewJwtManager(JwtConfig{Secret: "s3cr3t", Lifetime: time.Minute, Issuer: "irhabi"})
+	c := &testClaims{}
+	m.Sign(c)
+
+	assert.Equal(t, "irhabi", c.Issuer)
+	assert.InDelta(t, time.Now().Add(time.Minute).Unix(), c.ExpiresAt, 2)
+
+	c = &testClaims{}
+	c.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	token, _ := m.Sign(c)
+	_, err := m.Parse(token, &testClaims{})
+	assert.Equal(t, ErrJwtInvalid, err)
+}
+
+func TestJwtManagerRefresh(t *testing.T) {
+	m, _ := NewJwtManager(JwtConfig{Secret: "s3cr3t"})
+	p, err := m.Issue(&testClaims{UserID: 1, Role: "admin"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// access token cannot be used to refresh
+	_, err = m.Refresh(p.AccessToken, &testClaims{})
+	assert.Equal(t, ErrJwtInvalid, err)
+
+	c := &testClaims{}
+	np, err := m.Refresh(p.RefreshToken, c)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(1), c.UserID)
+		assert.Equal(t, "admin", c.Role)
+		assert.NotEqual(t, p.RefreshToken, np.RefreshToken)
+	}
+
+	// refresh token is revoked after exchanged
+	_, err = m.Refresh(p.RefreshToken, &testClaims{})
+	assert.Equal(t, ErrJwtRevoked, err)
+}
+
+func TestJwtManagerMiddleware(t *testing.T) {
+	m, _ := NewJwtManager(JwtConfig{Secret: "s3cr3t"})
+	p, _ := m.Issue(&testClaims{UserID: 1, Role: "admin"})
+
+	handler := func(c echo.Context) error {
+		return c.String(http.StatusOK, "test")
+	}
+
+	var cases = []struct {
+		auth     string
+		expected error
+	}{
+		{"", ErrJwtMissing},
+		{"Bearer " + p.RefreshToken, ErrJwtInvalid},
+		{"Bearer " + p.AccessToken, nil},
+	}
+
+	e := New()
+	for _, x := range cases {
+		req, _ := http.NewRequest(echo.GET, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, x.auth)
+		ctx := NewContext(e.NewContext(req, httptest.NewRecorder()))
+
+		assert.Equal(t, x.expected, m.Middleware()(handler)(ctx))
+		if x.expected == nil {
+			c := &testClaims{}
+			if assert.NoError(t, ctx.JwtClaims(c)) {
+				assert.Equal(t, "admin", c.Role)
+			}
+		}
+	}
+
+	assert.NoError(t, m.Revoke(p.AccessToken))
+	req, _ := http.NewRequest(echo.GET, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+p.AccessToken)
+	assert.Equal(t, ErrJwtRevoked, m.Middleware()(handler)(e.NewContext(req, httptest.NewRecorder())))
+}
+
+func TestJwtRequiresSecret(t *testing.T) {
+	defer func(c config, m *JwtManager) {
+		*Config = c
+		SetJwt(m)
+	}(*Config, Jwt())
+
+	reset := func() {
+		jwtOnce = sync.Once{}
+		jwtManager = nil
+	}
+
+	Config.JwtSecret, Config.JwtPrivateKey, Config.JwtPublicKey = "", "", ""
+	Config.DebugMode = false
+	reset()
+	assert.Panics(t, func() { Jwt() })
+
+	// debug mode is signed by random secret.
+	Config.DebugMode = true
+	reset()
+	if assert.NotPanics(t, func() { Jwt() }) {
+		assert.Len(t, Config.JwtSecret, 64)
+		assert.Equal(t, []byte(Config.JwtSecret), JwtKey())
+	}
+}
@@ -0,0 +1,267 @@
+package irhabi
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfatih/irhabi/orm"
+	"github.com/labstack/echo"
+)
+
+// DefaultMetrics metrics that collected by the default middlewares
+// and served on Config.MetricsRoute.
+var DefaultMetrics = NewMetrics()
+
+// MetricsBuckets upper bounds in seconds of the latency histograms.
+var MetricsBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Metrics collecting request and query metrics, and serving
+// it in prometheus text exposition format.
+type Metrics struct {
+	mu       sync.Mutex
+	requests map[string]*histogram // keyed by method, route and status
+	queries  map[string]*histogram // keyed by alias and operation
+	failures map[string]uint64     // keyed by alias and operation
+	observe  sync.Once
+}
+
+// dbStats connection pool stats of database alias.
+type dbStats struct {
+	alias string
+	sql.DBStats
+}
+
+// histogram cumulative latency histogram of single label set.
+type histogram struct {
+	labels  []string
+	buckets []uint64
+	count   uint64
+	sum     float64
+}
+
+// NewMetrics creates an empty metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		requests: make(map[string]*histogram),
+		queries:  make(map[string]*histogram),
+		failures: make(map[string]uint64),
+	}
+}
+
+// Middleware returns a middleware that recording count and latency
+// of the requests by method, route template and response status.
+func (m *Metrics) Middleware() echo.MiddlewareFunc {
+	return func(n echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			start := time.Now()
+			if err = n(c); err != nil {
+				c.Error(err)
+			}
+
+			m.ObserveRequest(c.Request().Method, c.Path(), c.Response().Status, time.Now().Sub(start))
+
+			return err
+		}
+	}
+}
+
+// ObserveRequest record the request latency.
+func (m *Metrics) ObserveRequest(method string, route string, status int, elapsed time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	observe(m.requests, elapsed, method, route, strconv.Itoa(status))
+}
+
+// ObserveQuery record the query latency, it can be registered
+// into orm.AddQueryObserver to collect queries of all database alias.
+func (m *Metrics) ObserveQuery(alias string, operation string, elapsed time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h := observe(m.queries, elapsed, alias, operation)
+	if err != nil {
+		m.failures[strings.Join(h.labels, "\x00")]++
+	}
+}
+
+// ObserveORM register the metrics as orm query observer, only once.
+func (m *Metrics) ObserveORM() {
+	m.observe.Do(func() {
+		orm.AddQueryObserver(m.ObserveQuery)
+	})
+}
+
+// Handler returns handler that serving the metrics.
+func (m *Metrics) Handler() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		c.Response().Header().Set(echo.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+		c.Response().WriteHeader(http.StatusOK)
+
+		_, err := m.WriteTo(c.Response())
+		return err
+	}
+}
+
+// WriteTo write the metrics and the database connection pool stats
+// of all registered database alias in prometheus text exposition format.
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+
+	m.mu.Lock()
+	writeHistograms(&b, "irhabi_http_request_duration_seconds", "Latency of http requests.", []string{"method", "route", "status"}, m.requests)
+	writeCounters(&b, "irhabi_http_requests_total", "Total of http requests.", []string{"method", "route", "status"}, m.requests, nil)
+	writeHistograms(&b, "irhabi_db_query_duration_seconds", "Latency of database queries.", []string{"alias", "operation"}, m.queries)
+	writeCounters(&b, "irhabi_db_queries_total", "Total of database queries.", []string{"alias", "operation"}, m.queries, nil)
+	writeCounters(&b, "irhabi_db_query_errors_total", "Total of failed database queries.", []string{"alias", "operation"}, m.queries, m.failures)
+	m.mu.Unlock()
+
+	writeDbStats(&b)
+
+	n, err := w.Write(b.Bytes())
+	return int64(n), err
+}
+
+// observe record the latency into histogram of the labels.
+func observe(hs map[string]*histogram, elapsed time.Duration, labels ...string) *histogram {
+	key := strings.Join(labels, "\x00")
+	h, ok := hs[key]
+	if !ok {
+		h = &histogram{labels: labels, buckets: make([]uint64, len(MetricsBuckets))}
+		hs[key] = h
+	}
+
+	s := elapsed.Seconds()
+	for i, le := range MetricsBuckets {
+		if s <= le {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += s
+
+	return h
+}
+
+// writeHistograms write histograms in sorted order of the labels.
+func writeHistograms(w io.Writer, name string, help string, names []string, hs map[string]*histogram) {
+	if len(hs) == 0 {
+		return
+	}
+
+	writeHeader(w, name, help, "histogram")
+	for _, key := range sortedKeys(hs) {
+		h := hs[key]
+		for i, le := range MetricsBuckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", name, formatLabels(withLabel(names, "le"), withLabel(h.labels, formatFloat(le))), h.buckets[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", name, formatLabels(withLabel(names, "le"), withLabel(h.labels, "+Inf")), h.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", name, formatLabels(names, h.labels), formatFloat(h.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", name, formatLabels(names, h.labels), h.count)
+	}
+}
+
+// writeCounters write count of the histograms, or the values if not nil.
+func writeCounters(w io.Writer, name string, help string, names []string, hs map[string]*histogram, values map[string]uint64) {
+	if len(hs) == 0 {
+		return
+	}
+
+	writeHeader(w, name, help, "counter")
+	for _, key := range sortedKeys(hs) {
+		v := hs[key].count
+		if values != nil {
+			v = values[key]
+		}
+
+		fmt.Fprintf(w, "%s%s %d\n", name, formatLabels(names, hs[key].labels), v)
+	}
+}
+
+// writeDbStats write connection pool stats of all registered database alias.
+func writeDbStats(w io.Writer) {
+	aliases := orm.DataBaseAliases()
+	if len(aliases) == 0 {
+		return
+	}
+
+	var gauges = []struct {
+		name  string
+		help  string
+		typ   string
+		value func(s dbStats) string
+	}{
+		{"irhabi_db_max_open_connections", "Maximum number of open connections to the database.", "gauge", func(s dbStats) string { return strconv.Itoa(s.MaxOpenConnections) }},
+		{"irhabi_db_open_connections", "Number of established connections to the database.", "gauge", func(s dbStats) string { return strconv.Itoa(s.OpenConnections) }},
+		{"irhabi_db_in_use_connections", "Number of connections currently in use.", "gauge", func(s dbStats) string { return strconv.Itoa(s.InUse) }},
+		{"irhabi_db_idle_connections", "Number of idle connections.", "gauge", func(s dbStats) string { return strconv.Itoa(s.Idle) }},
+		{"irhabi_db_wait_count_total", "Total number of connections waited for.", "counter", func(s dbStats) string { return strconv.FormatInt(s.WaitCount, 10) }},
+		{"irhabi_db_wait_duration_seconds_total", "Total time blocked waiting for new connections.", "counter", func(s dbStats) string { return formatFloat(s.WaitDuration.Seconds()) }},
+	}
+
+	stats := make([]dbStats, 0, len(aliases))
+	for _, a := range aliases {
+		if db, err := orm.GetDB(a); err == nil {
+			stats = append(stats, dbStats{a, db.Stats()})
+		}
+	}
+
+	for _, g := range gauges {
+		writeHeader(w, g.name, g.help, g.typ)
+		for _, s := range stats {
+			fmt.Fprintf(w, "%s%s %s\n", g.name, formatLabels([]string{"alias"}, []string{s.alias}), g.value(s))
+		}
+	}
+}
+
+// writeHeader write help and type of the metric.
+func writeHeader(w io.Writer, name string, help string, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// formatLabels format label names and values.
+func formatLabels(names []string, values []string) string {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, n := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, n, labelReplacer.Replace(values[i]))
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+// withLabel returns copy of the labels with additional label.
+func withLabel(labels []string, label string) []string {
+	return append(append(make([]string, 0, len(labels)+1), labels...), label)
+}
+
+// labelReplacer escaping label values.
+var labelReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// formatFloat format float value of metrics.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// sortedKeys returns keys of the histograms in sorted order.
+func sortedKeys(hs map[string]*histogram) []string {
+	keys := make([]string, 0, len(hs))
+	for k := range hs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
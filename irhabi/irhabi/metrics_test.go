@@ -0,0 +1,64 @@
+package irhabi
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	m.ObserveRequest(echo.GET, "/users/:id", http.StatusOK, 20*time.Millisecond)
+	m.ObserveRequest(echo.GET, "/users/:id", http.StatusOK, 2*time.Second)
+	m.ObserveQuery("default", "db.Query", 3*time.Millisecond, nil)
+	m.ObserveQuery("default", "db.Query", 3*time.Millisecond, errors.New("failed"))
+
+	var b bytes.Buffer
+	_, err := m.WriteTo(&b)
+	assert.NoError(t, err)
+
+	out := b.String()
+	assert.Contains(t, out, "# TYPE irhabi_http_request_duration_seconds histogram\n")
+	assert.Contains(t, out, `irhabi_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="200",le="0.01"} 0`)
+	assert.Contains(t, out, `irhabi_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="200",le="0.025"} 1`)
+	assert.Contains(t, out, `irhabi_http_request_duration_seconds_bucket{method="GET",route="/users/:id",status="200",le="+Inf"} 2`)
+	assert.Contains(t, out, `irhabi_http_request_duration_seconds_sum{method="GET",route="/users/:id",status="200"} 2.02`)
+	assert.Contains(t, out, `irhabi_http_requests_total{method="GET",route="/users/:id",status="200"} 2`)
+	assert.Contains(t, out, `irhabi_db_query_duration_seconds_bucket{alias="default",operation="db.Query",le="0.005"} 2`)
+	assert.Contains(t, out, `irhabi_db_queries_total{alias="default",operation="db.Query"} 2`)
+	assert.Contains(t, out, `irhabi_db_query_errors_total{alias="default",operation="db.Query"} 1`)
+}
+
+func TestMetricsRoute(t *testing.T) {
+	route := Config.MetricsRoute
+	defer func() {
+		Config.MetricsRoute = route
+	}()
+
+	Config.MetricsRoute = "/metrics"
+	e := New()
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, "OK")
+	})
+	e.GET("/fail", func(c echo.Context) error {
+		return echo.ErrNotFound
+	})
+
+	request(echo.GET, "/users/1", e)
+	request(echo.GET, "/fail", e)
+
+	req, _ := http.NewRequest(echo.GET, "/metrics", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get(echo.HeaderContentType), "text/plain; version=0.0.4")
+	assert.Contains(t, rec.Body.String(), `irhabi_http_requests_total{method="GET",route="/users/:id",status="200"} 1`)
+	assert.Contains(t, rec.Body.String(), `irhabi_http_requests_total{method="GET",route="/fail",status="404"} 1`)
+}
@@ -30,7 +30,7 @@ func logRequest(hand echo.HandlerFunc, c echo.Context) (err error) {
 	end := time.Now()
 	latency := end.Sub(start) / 1e5
 
-	log := log.Log.WithFields(logrus.Fields{
+	log := log.FromContext(c.Request().Context()).WithFields(logrus.Fields{
 		"prefix":    fmt.Sprintf("%s/%d", req.Method, res.Status),
 		"latecy":    fmt.Sprintf("%1.1fms", float64(int(latency))/10.0),
 		"requester": req.RemoteAddr,
@@ -0,0 +1,319 @@
+package mw
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alfatih/irhabi/orm"
+	"github.com/labstack/echo"
+)
+
+const (
+	// HeaderIdempotencyKey header that carrying the idempotency key of the request.
+	HeaderIdempotencyKey = "Idempotency-Key"
+
+	// HeaderIdempotentReplayed header of the replayed responses.
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+var (
+	// ErrIdempotencyConflict error when the request of the same key is still in progress.
+	ErrIdempotencyConflict = echo.NewHTTPError(http.StatusConflict, "A request with the same Idempotency-Key is still in progress.")
+
+	// ErrIdempotencyMismatch error when the key is reused with different request.
+	ErrIdempotencyMismatch = echo.NewHTTPError(http.StatusUnprocessableEntity, "The Idempotency-Key was used with a different request.")
+)
+
+type (
+	// IdempotencyStore storage of the idempotency records.
+	IdempotencyStore interface {
+		// Begin reserve the key for the request hash until ttl, it returns
+		// the stored record when the key is already reserved or completed.
+		Begin(key string, hash string, ttl time.Duration) (*IdempotencyRecord, error)
+		// Complete store the response of the reserved key until ttl.
+		Complete(r *IdempotencyRecord, ttl time.Duration) error
+		// Release remove the reservation so the request can be retried.
+		Release(key string) error
+	}
+
+	// IdempotencyRecord stored response of the idempotency key,
+	// register the model with `orm.RegisterModel(new(mw.IdempotencyRecord))` to use NewIdempotencyOrmStore.
+	IdempotencyRecord struct {
+		Key         string    `orm:"column(idempotency_key);pk;size(64)" json:"key"`
+		Hash        string    `orm:"column(request_hash);size(64)" json:"hash"`
+		Done        bool      `orm:"column(done)" json:"done"`
+		Status      int       `orm:"column(status)" json:"status"`
+		ContentType string    `orm:"column(content_type);size(255)" json:"content_type"`
+		Body        string    `orm:"column(body);type(text)" json:"body"`
+		ExpiresAt   time.Time `orm:"column(expires_at);type(datetime)" json:"-"`
+	}
+
+	// IdempotencyConfig configuration of idempotency middleware.
+	IdempotencyConfig struct {
+		Store       IdempotencyStore          // Storage of the responses
+		TTL         time.Duration             // Time the response is replayed, default is 24 hours
+		LockTimeout time.Duration             // Time the key is reserved while in progress, default is 1 minute
+		KeyFunc     func(echo.Context) string // Owner of the keys, default is RateLimitByUser
+		Methods     []string                  // Methods that honor the key, default is POST and PATCH
+	}
+
+	// idempotencyWriter response writer that copying the body.
+	idempotencyWriter struct {
+		http.ResponseWriter
+		body bytes.Buffer
+	}
+)
+
+// TableName returns table name of the idempotency records.
+func (r *IdempotencyRecord) TableName() string {
+	return "idempotency_key"
+}
+
+// Idempotency returns a middleware that honor Idempotency-Key header
+// on POST and PATCH requests using the store.
+func Idempotency(store IdempotencyStore) echo.MiddlewareFunc {
+	return IdempotencyWithConfig(IdempotencyConfig{Store: store})
+}
+
+// IdempotencyWithConfig returns a middleware that storing the first response of the
+// Idempotency-Key per user, retries within TTL get the stored response replayed with
+// Idempotent-Replayed header. Duplicate while the first request is still in progress
+// returns ErrIdempotencyConflict and the key that reused with different request body
+// returns ErrIdempotencyMismatch. Server errors are not stored so the request can be retried.
+// It should be registered after the jwt middleware so the keys are owned by the user.
+func IdempotencyWithConfig(config IdempotencyConfig) echo.MiddlewareFunc {
+	if config.TTL == 0 {
+		config.TTL = 24 * time.Hour
+	}
+
+	if config.LockTimeout == 0 {
+		config.LockTimeout = time.Minute
+	}
+
+	if config.KeyFunc == nil {
+		config.KeyFunc = RateLimitByUser
+	}
+
+	if len(config.Methods) == 0 {
+		config.Methods = []string{echo.POST, echo.PATCH}
+	}
+
+	return func(n echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			req := c.Request()
+			ik := req.Header.Get(HeaderIdempotencyKey)
+			if ik == "" || !contains(config.Methods, req.Method) {
+				return n(c)
+			}
+
+			var body []byte
+			if req.Body != nil {
+				if body, err = ioutil.ReadAll(req.Body); err != nil {
+					return err
+				}
+				req.Body = ioutil.NopCloser(bytes.NewReader(body))
+			}
+
+			key := hashOf(config.KeyFunc(c), ik)
+			hash := hashOf(req.Method, req.URL.Path, string(body))
+
+			var r *IdempotencyRecord
+			if r, err = config.Store.Begin(key, hash, config.LockTimeout); err != nil {
+				return err
+			}
+
+			if r != nil {
+				switch {
+				case r.Hash != hash:
+					return ErrIdempotencyMismatch
+				case !r.Done:
+					return ErrIdempotencyConflict
+				}
+
+				c.Response().Header().Set(HeaderIdempotentReplayed, "true")
+				return c.Blob(r.Status, r.ContentType, []byte(r.Body))
+			}
+
+			// release the reservation when the handler is panic.
+			done := false
+			defer func() {
+				if !done {
+					config.Store.Release(key)
+				}
+			}()
+
+			res := c.Response()
+			w := &idempotencyWriter{ResponseWriter: res.Writer}
+			res.Writer = w
+			if err = n(c); err != nil {
+				c.Error(err)
+			}
+			res.Writer = w.ResponseWriter
+
+			if res.Status >= http.StatusInternalServerError {
+				return nil
+			}
+
+			done = true
+			return config.Store.Complete(&IdempotencyRecord{
+				Key:         key,
+				Hash:        hash,
+				Done:        true,
+				Status:      res.Status,
+				ContentType: res.Header().Get(echo.HeaderContentType),
+				Body:        w.body.String(),
+			}, config.TTL)
+		}
+	}
+}
+
+// Write copy the body before written into the response.
+func (w *idempotencyWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+// idempotencyCacheStore idempotency store of the Cache.
+type idempotencyCacheStore struct {
+	mu    sync.Mutex
+	cache Cache
+}
+
+// NewIdempotencyCacheStore creates idempotency store using cache e.g NewMemoryCache
+// or beego cache adapters, the reservation is only atomic within the application instance.
+func NewIdempotencyCacheStore(cache Cache) IdempotencyStore {
+	return &idempotencyCacheStore{cache: cache}
+}
+
+// Begin reserve the key when not exists.
+func (s *idempotencyCacheStore) Begin(key string, hash string, ttl time.Duration) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key = "idempotency:" + key
+	if v := toString(s.cache.Get(key)); v != "" {
+		r := new(IdempotencyRecord)
+		if err := json.Unmarshal([]byte(v), r); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+
+	return nil, s.put(&IdempotencyRecord{Key: key, Hash: hash}, ttl)
+}
+
+// Complete store the response.
+func (s *idempotencyCacheStore) Complete(r *IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r.Key = "idempotency:" + r.Key
+	return s.put(r, ttl)
+}
+
+// Release remove the key.
+func (s *idempotencyCacheStore) Release(key string) error {
+	return s.cache.Delete("idempotency:" + key)
+}
+
+// put store json of the record.
+func (s *idempotencyCacheStore) put(r *IdempotencyRecord, ttl time.Duration) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	return s.cache.Put(r.Key, string(b), ttl)
+}
+
+// idempotencyOrmStore idempotency store of the database.
+type idempotencyOrmStore struct {
+	alias string
+}
+
+// NewIdempotencyOrmStore creates idempotency store using table of IdempotencyRecord
+// on the database alias, empty alias is the default database.
+// The model should be registered before orm.RunSyncdb or orm.BootStrap.
+func NewIdempotencyOrmStore(alias string) IdempotencyStore {
+	if alias == "" {
+		alias = "default"
+	}
+
+	return &idempotencyOrmStore{alias: alias}
+}
+
+// Begin insert the key, expired record of the key is replaced.
+func (s *idempotencyOrmStore) Begin(key string, hash string, ttl time.Duration) (*IdempotencyRecord, error) {
+	o := s.orm()
+	_, err := o.Insert(&IdempotencyRecord{Key: key, Hash: hash, ExpiresAt: time.Now().Add(ttl)})
+	if err == nil {
+		return nil, nil
+	}
+
+	// failure that not caused by the existing key.
+	r := &IdempotencyRecord{Key: key}
+	if o.Read(r) != nil {
+		return nil, err
+	}
+
+	if time.Now().Before(r.ExpiresAt) {
+		return r, nil
+	}
+
+	if n, err := o.QueryTable(r).Filter("idempotency_key", key).Filter("expires_at__lt", time.Now()).Delete(); err != nil || n == 0 {
+		return r, err
+	}
+
+	return s.Begin(key, hash, ttl)
+}
+
+// Complete update the record of the key.
+func (s *idempotencyOrmStore) Complete(r *IdempotencyRecord, ttl time.Duration) error {
+	r.ExpiresAt = time.Now().Add(ttl)
+	_, err := s.orm().Update(r)
+
+	return err
+}
+
+// Release delete the record of the key.
+func (s *idempotencyOrmStore) Release(key string) error {
+	_, err := s.orm().Delete(&IdempotencyRecord{Key: key})
+
+	return err
+}
+
+// orm returns orm of the database alias.
+func (s *idempotencyOrmStore) orm() orm.Ormer {
+	o := orm.NewOrm()
+	o.Using(s.alias)
+
+	return o
+}
+
+// hashOf returns hex of sha256 of the values.
+func hashOf(values ...string) string {
+	h := sha256.New()
+	for _, v := range values {
+		h.Write([]byte(v))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// contains returns true when the value is in the list.
+func contains(l []string, v string) bool {
+	for _, i := range l {
+		if i == v {
+			return true
+		}
+	}
+
+	return false
+}
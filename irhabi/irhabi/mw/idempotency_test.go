@@ -0,0 +1,135 @@
+package mw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alfatih/irhabi/orm"
+	"github.com/labstack/echo"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	orm.RegisterModel(new(IdempotencyRecord))
+	orm.RegisterDataBase("default", "sqlite3", "file::memory:?cache=shared", 1, 1)
+}
+
+func idempotencyServer(store IdempotencyStore) (*echo.Echo, *int32, chan struct{}) {
+	var calls int32
+	wait := make(chan struct{})
+
+	e := echo.New()
+	e.Use(Idempotency(store))
+	e.POST("/order", func(c echo.Context) error {
+		n := atomic.AddInt32(&calls, 1)
+		return c.JSON(http.StatusOK, map[string]interface{}{"id": n})
+	})
+	e.POST("/slow", func(c echo.Context) error {
+		wait <- struct{}{}
+		<-wait
+		return c.NoContent(http.StatusNoContent)
+	})
+	e.POST("/invalid", func(c echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return echo.NewHTTPError(http.StatusUnprocessableEntity)
+	})
+	e.POST("/fail", func(c echo.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return echo.NewHTTPError(http.StatusInternalServerError)
+	})
+
+	return e, &calls, wait
+}
+
+func idempotencyRequest(e *echo.Echo, path string, key string, body string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest(echo.POST, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(HeaderIdempotencyKey, key)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	return rec
+}
+
+func testIdempotency(t *testing.T, store IdempotencyStore) {
+	e, calls, wait := idempotencyServer(store)
+
+	rec := idempotencyRequest(e, "/order", "a", `{"qty":1}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "{\"id\":1}\n", rec.Body.String())
+	assert.Equal(t, "", rec.Header().Get(HeaderIdempotentReplayed))
+
+	rec = idempotencyRequest(e, "/order", "a", `{"qty":1}`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "{\"id\":1}\n", rec.Body.String())
+	assert.Equal(t, echo.MIMEApplicationJSONCharsetUTF8, rec.Header().Get(echo.HeaderContentType))
+	assert.Equal(t, "true", rec.Header().Get(HeaderIdempotentReplayed))
+
+	rec = idempotencyRequest(e, "/order", "a", `{"qty":2}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+
+	rec = idempotencyRequest(e, "/order", "b", `{"qty":1}`)
+	assert.Equal(t, "{\"id\":2}\n", rec.Body.String())
+
+	rec = idempotencyRequest(e, "/order", "", `{"qty":1}`)
+	assert.Equal(t, "{\"id\":3}\n", rec.Body.String())
+
+	// client failure is stored, server failure can be retried.
+	idempotencyRequest(e, "/invalid", "c", "")
+	rec = idempotencyRequest(e, "/invalid", "c", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, int32(4), atomic.LoadInt32(calls))
+
+	idempotencyRequest(e, "/fail", "d", "")
+	rec = idempotencyRequest(e, "/fail", "d", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, int32(6), atomic.LoadInt32(calls))
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- idempotencyRequest(e, "/slow", "e", "")
+	}()
+
+	<-wait
+	rec = idempotencyRequest(e, "/slow", "e", "")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	close(wait)
+	assert.Equal(t, http.StatusNoContent, (<-done).Code)
+	rec = idempotencyRequest(e, "/slow", "e", "")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "true", rec.Header().Get(HeaderIdempotentReplayed))
+}
+
+func TestIdempotencyCacheStore(t *testing.T) {
+	testIdempotency(t, NewIdempotencyCacheStore(NewMemoryCache()))
+}
+
+func TestIdempotencyOrmStore(t *testing.T) {
+	if !assert.NoError(t, orm.RunSyncdb("default", true, false)) {
+		return
+	}
+
+	s := NewIdempotencyOrmStore("")
+	testIdempotency(t, s)
+
+	// expired key is reserved again.
+	r, err := s.Begin("x", "h", -time.Second)
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+
+	r, err = s.Begin("x", "h", time.Minute)
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+
+	r, err = s.Begin("x", "h", time.Minute)
+	assert.NoError(t, err)
+	if assert.NotNil(t, r) {
+		assert.False(t, r.Done)
+	}
+}
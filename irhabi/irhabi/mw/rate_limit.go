@@ -0,0 +1,344 @@
+package mw
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+)
+
+// ErrTooManyRequests error when the client is exceeding the rate limit.
+var ErrTooManyRequests = echo.NewHTTPError(http.StatusTooManyRequests)
+
+type (
+	// Cache is subset of beego/cache Cache interface, so any of beego cache
+	// adapters (memory, redis, memcache) can be used to share the counters.
+	Cache interface {
+		Get(key string) interface{}
+		Put(key string, val interface{}, timeout time.Duration) error
+		Incr(key string) error
+		IsExist(key string) bool
+		Delete(key string) error
+	}
+
+	// Limiter deciding is the request of the key allowed.
+	Limiter interface {
+		Allow(key string) RateResult
+	}
+
+	// RateResult result of rate limiter.
+	RateResult struct {
+		Allowed    bool          // State of the request is allowed
+		Limit      int           // Maximum request allowed
+		Remaining  int           // Remaining request allowed
+		Reset      time.Duration // Time until the limit fully reset
+		RetryAfter time.Duration // Time until next request allowed
+	}
+
+	// RateLimitConfig configuration of rate limiter middleware.
+	RateLimitConfig struct {
+		Limiter Limiter                   // Default limiter of all routes
+		KeyFunc func(echo.Context) string // Client identifier, default is RateLimitByIP
+		Routes  map[string]Limiter        // Limiter override by "METHOD /route/:path"
+	}
+)
+
+// RateLimit returns a middleware that limiting the requests
+// of client ip using the limiter.
+func RateLimit(l Limiter) echo.MiddlewareFunc {
+	return RateLimitWithConfig(RateLimitConfig{Limiter: l})
+}
+
+// RateLimitWithConfig returns a middleware that limiting the requests
+// with config, limiter is selected by the route and the counters keyed by KeyFunc.
+// Every response has X-RateLimit-Limit, X-RateLimit-Remaining and X-RateLimit-Reset headers,
+// and exceeding requests returns 429 errors with Retry-After header.
+func RateLimitWithConfig(config RateLimitConfig) echo.MiddlewareFunc {
+	if config.KeyFunc == nil {
+		config.KeyFunc = RateLimitByIP
+	}
+
+	return func(n echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			route := c.Request().Method + " " + c.Path()
+			key := config.KeyFunc(c)
+
+			l := config.Limiter
+			if rl, ok := config.Routes[route]; ok {
+				l, key = rl, route+":"+key
+			}
+
+			if l == nil {
+				return n(c)
+			}
+
+			r := l.Allow(key)
+			h := c.Response().Header()
+			h.Set("X-RateLimit-Limit", strconv.Itoa(r.Limit))
+			h.Set("X-RateLimit-Remaining", strconv.Itoa(r.Remaining))
+			h.Set("X-RateLimit-Reset", strconv.Itoa(ceilSeconds(r.Reset)))
+
+			if !r.Allowed {
+				h.Set("Retry-After", strconv.Itoa(ceilSeconds(r.RetryAfter)))
+				return ErrTooManyRequests
+			}
+
+			return n(c)
+		}
+	}
+}
+
+// RateLimitByIP identify the client by real ip address.
+func RateLimitByIP(c echo.Context) string {
+	return "ip:" + c.RealIP()
+}
+
+// RateLimitByUser identify the client by id claim of jwt token,
+// requests without jwt token are identified by the ip address.
+func RateLimitByUser(c echo.Context) string {
+	if t, ok := c.Get("user").(*jwt.Token); ok {
+		if mc, ok := t.Claims.(jwt.MapClaims); ok && mc["id"] != nil {
+			return fmt.Sprintf("user:%v", mc["id"])
+		}
+	}
+
+	return RateLimitByIP(c)
+}
+
+// RateLimitByHeader identify the client by the header value e.g api key,
+// requests without the header are identified by the ip address.
+func RateLimitByHeader(name string) func(echo.Context) string {
+	return func(c echo.Context) string {
+		if v := c.Request().Header.Get(name); v != "" {
+			return "key:" + v
+		}
+
+		return RateLimitByIP(c)
+	}
+}
+
+// tokenBucket limiter that refill the tokens at constant rate.
+type tokenBucket struct {
+	mu    sync.Mutex
+	cache Cache
+	rate  float64 // tokens per nanosecond
+	burst int
+}
+
+// NewTokenBucket creates token bucket limiter that allowing burst requests
+// and refilled with rate requests per duration, the buckets stored in cache.
+func NewTokenBucket(rate int, per time.Duration, burst int, cache Cache) Limiter {
+	return &tokenBucket{
+		cache: cache,
+		rate:  float64(rate) / float64(per),
+		burst: burst,
+	}
+}
+
+// Allow consume one token from the bucket of the key.
+func (l *tokenBucket) Allow(key string) (r RateResult) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	key = "ratelimit:tb:" + key
+	now := time.Now().UnixNano()
+	tokens, last := float64(l.burst), now
+	if v := toString(l.cache.Get(key)); v != "" {
+		if p := strings.SplitN(v, "|", 2); len(p) == 2 {
+			tokens, _ = strconv.ParseFloat(p[0], 64)
+			last, _ = strconv.ParseInt(p[1], 10, 64)
+		}
+	}
+
+	tokens = math.Min(float64(l.burst), tokens+float64(now-last)*l.rate)
+	r.Limit = l.burst
+	if tokens >= 1 {
+		tokens--
+		r.Allowed = true
+	} else {
+		r.RetryAfter = time.Duration((1 - tokens) / l.rate)
+	}
+
+	r.Remaining = int(tokens)
+	r.Reset = time.Duration((float64(l.burst) - tokens) / l.rate)
+	l.cache.Put(key, fmt.Sprintf("%f|%d", tokens, now), r.Reset+time.Second)
+
+	return r
+}
+
+// slidingWindow limiter that counting the requests on the last window,
+// estimated from counter of the current and previous fixed window.
+type slidingWindow struct {
+	mu     sync.Mutex
+	cache  Cache
+	limit  int
+	window time.Duration
+}
+
+// NewSlidingWindow creates sliding window limiter that allowing
+// limit requests per window, the counters stored in cache.
+func NewSlidingWindow(limit int, window time.Duration, cache Cache) Limiter {
+	return &slidingWindow{
+		cache:  cache,
+		limit:  limit,
+		window: window,
+	}
+}
+
+// Allow counting the request of the key.
+func (l *slidingWindow) Allow(key string) (r RateResult) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now().UnixNano()
+	idx := now / int64(l.window)
+	elapsed := float64(now%int64(l.window)) / float64(l.window)
+
+	ck := fmt.Sprintf("ratelimit:sw:%s:%d", key, idx)
+	pk := fmt.Sprintf("ratelimit:sw:%s:%d", key, idx-1)
+	prev, cur := toInt(l.cache.Get(pk)), toInt(l.cache.Get(ck))
+	count := int(float64(prev)*(1-elapsed)) + cur
+
+	r.Limit = l.limit
+	r.Reset = time.Duration(int64(l.window) - now%int64(l.window))
+	if count >= l.limit {
+		r.RetryAfter = r.Reset
+		return r
+	}
+
+	if cur == 0 || !l.cache.IsExist(ck) {
+		l.cache.Put(ck, 1, 2*l.window)
+	} else {
+		l.cache.Incr(ck)
+	}
+
+	r.Allowed = true
+	r.Remaining = l.limit - count - 1
+
+	return r
+}
+
+// memoryCache in memory implementation of the Cache.
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+	gc    time.Time
+}
+
+// memoryItem cached value with expiration time.
+type memoryItem struct {
+	val     interface{}
+	expires time.Time
+}
+
+// NewMemoryCache creates in memory cache to store rate limiter counters,
+// the counters are not shared with other instances of application.
+func NewMemoryCache() Cache {
+	return &memoryCache{items: make(map[string]memoryItem), gc: time.Now()}
+}
+
+// Get returns the value of the key if not expired.
+func (m *memoryCache) Get(key string) interface{} {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if i, ok := m.items[key]; ok && time.Now().Before(i.expires) {
+		return i.val
+	}
+
+	return nil
+}
+
+// Put set the value of the key, expired items are cleaned up every minutes.
+func (m *memoryCache) Put(key string, val interface{}, timeout time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.gc) > time.Minute {
+		for k, i := range m.items {
+			if now.After(i.expires) {
+				delete(m.items, k)
+			}
+		}
+		m.gc = now
+	}
+
+	m.items[key] = memoryItem{val: val, expires: now.Add(timeout)}
+	return nil
+}
+
+// Incr increase the integer value of the key.
+func (m *memoryCache) Incr(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	i, ok := m.items[key]
+	if !ok {
+		return fmt.Errorf("key not exist")
+	}
+
+	i.val = toInt(i.val) + 1
+	m.items[key] = i
+	return nil
+}
+
+// Delete remove the key.
+func (m *memoryCache) Delete(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.items, key)
+	return nil
+}
+
+// IsExist check the key is exists and not expired.
+func (m *memoryCache) IsExist(key string) bool {
+	return m.Get(key) != nil
+}
+
+// toString convert value from cache into string.
+func toString(v interface{}) string {
+	switch x := v.(type) {
+	case string:
+		return x
+	case []byte:
+		return string(x)
+	case nil:
+		return ""
+	}
+
+	return fmt.Sprint(v)
+}
+
+// toInt convert value from cache into int.
+func toInt(v interface{}) int {
+	switch x := v.(type) {
+	case int:
+		return x
+	case int32:
+		return int(x)
+	case int64:
+		return int(x)
+	case uint:
+		return int(x)
+	case uint32:
+		return int(x)
+	case uint64:
+		return int(x)
+	}
+
+	i, _ := strconv.Atoi(toString(v))
+	return i
+}
+
+// ceilSeconds round up duration into seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
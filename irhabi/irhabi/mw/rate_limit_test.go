@@ -0,0 +1,99 @@
+package mw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucket(t *testing.T) {
+	l := NewTokenBucket(1, 50*time.Millisecond, 2, NewMemoryCache())
+
+	assert.True(t, l.Allow("a").Allowed)
+	r := l.Allow("a")
+	assert.True(t, r.Allowed)
+	assert.Equal(t, 0, r.Remaining)
+
+	r = l.Allow("a")
+	assert.False(t, r.Allowed)
+	assert.True(t, r.RetryAfter > 0)
+	assert.True(t, l.Allow("b").Allowed)
+
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, l.Allow("a").Allowed)
+}
+
+func TestSlidingWindow(t *testing.T) {
+	l := NewSlidingWindow(3, time.Hour, NewMemoryCache())
+
+	for i := 2; i >= 0; i-- {
+		r := l.Allow("a")
+		assert.True(t, r.Allowed)
+		assert.Equal(t, i, r.Remaining)
+		assert.Equal(t, 3, r.Limit)
+	}
+
+	r := l.Allow("a")
+	assert.False(t, r.Allowed)
+	assert.Equal(t, r.Reset, r.RetryAfter)
+	assert.True(t, l.Allow("b").Allowed)
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Use(RateLimitWithConfig(RateLimitConfig{
+		Limiter: NewSlidingWindow(2, time.Hour, NewMemoryCache()),
+		KeyFunc: RateLimitByUser,
+		Routes: map[string]Limiter{
+			"POST /login": NewSlidingWindow(1, time.Hour, NewMemoryCache()),
+		},
+	}))
+
+	h := func(c echo.Context) error {
+		return c.String(http.StatusOK, "OK")
+	}
+	e.GET("/search", h)
+	e.POST("/login", h)
+
+	request := func(method, path, ip string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, nil)
+		req.Header.Set(echo.HeaderXRealIP, ip)
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := request(echo.GET, "/search", "10.0.0.1")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", rec.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, http.StatusOK, request(echo.GET, "/search", "10.0.0.1").Code)
+
+	rec = request(echo.GET, "/search", "10.0.0.1")
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.NotEmpty(t, rec.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, request(echo.GET, "/search", "10.0.0.2").Code)
+
+	assert.Equal(t, http.StatusOK, request(echo.POST, "/login", "10.0.0.1").Code)
+	assert.Equal(t, http.StatusTooManyRequests, request(echo.POST, "/login", "10.0.0.1").Code)
+}
+
+func TestRateLimitKeys(t *testing.T) {
+	e := echo.New()
+	req, _ := http.NewRequest(echo.GET, "/", nil)
+	req.Header.Set(echo.HeaderXRealIP, "10.0.0.1")
+	req.Header.Set("X-API-Key", "s3cr3t")
+	c := e.NewContext(req, httptest.NewRecorder())
+
+	assert.Equal(t, "ip:10.0.0.1", RateLimitByIP(c))
+	assert.Equal(t, "ip:10.0.0.1", RateLimitByUser(c))
+	assert.Equal(t, "key:s3cr3t", RateLimitByHeader("X-API-Key")(c))
+
+	c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"id": float64(1)}})
+	assert.Equal(t, "user:1", RateLimitByUser(c))
+}
@@ -0,0 +1,51 @@
+package mw
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/alfatih/irhabi/common/log"
+	"github.com/labstack/echo"
+)
+
+const (
+	// HeaderRequestID header that carrying the request id.
+	HeaderRequestID = "X-Request-ID"
+
+	// RequestIDKey key of the request id stored in echo.Context.
+	RequestIDKey = "request_id"
+)
+
+// RequestID returns a middleware that accepting X-Request-ID header from the client
+// or generating new one, the id is stored in the context and returned in the response headers.
+// Log entry with the id is carried by context of the request, see log.FromContext,
+// that used by HTTPLogger and the queries of orm.NewOrmWithContext.
+// Only the entries of the context carry the id, the plain log functions
+// and orm.NewOrm are logged without it.
+// It should be registered before HTTPLogger so the request log also has the id.
+func RequestID() echo.MiddlewareFunc {
+	return func(n echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			id := c.Request().Header.Get(HeaderRequestID)
+			if id == "" || len(id) > 128 {
+				id = requestID()
+			}
+
+			c.Set(RequestIDKey, id)
+			c.Response().Header().Set(HeaderRequestID, id)
+
+			req := c.Request()
+			c.SetRequest(req.WithContext(log.NewContext(req.Context(), log.Log.WithField(RequestIDKey, id))))
+
+			return n(c)
+		}
+	}
+}
+
+// requestID generate random request id.
+func requestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,39 @@
+package mw
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alfatih/irhabi/common/log"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	var output bytes.Buffer
+	defer func(out io.Writer) { log.Log.Out = out }(log.Log.Out)
+	log.Log.Out = &output
+
+	e := echo.New()
+	h := RequestID()(func(c echo.Context) error {
+		log.FromContext(c.Request().Context()).Info("handling request")
+		return c.String(http.StatusOK, c.Get(RequestIDKey).(string))
+	})
+
+	req, _ := http.NewRequest(echo.GET, "/", nil)
+	req.Header.Set(HeaderRequestID, "abc-123")
+	rec := httptest.NewRecorder()
+	assert.NoError(t, h(e.NewContext(req, rec)))
+	assert.Equal(t, "abc-123", rec.Header().Get(HeaderRequestID))
+	assert.Equal(t, "abc-123", rec.Body.String())
+	assert.Contains(t, output.String(), `"request_id":"abc-123"`)
+
+	req, _ = http.NewRequest(echo.GET, "/", nil)
+	rec = httptest.NewRecorder()
+	assert.NoError(t, h(e.NewContext(req, rec)))
+	assert.Len(t, rec.Header().Get(HeaderRequestID), 32)
+	assert.Equal(t, rec.Header().Get(HeaderRequestID), rec.Body.String())
+}
@@ -0,0 +1,30 @@
+package mw
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo"
+)
+
+// Timeout returns a middleware that cancel the request context
+// when the request is taking longer than the duration,
+// if the response is not committed yet it will returns 503 errors.
+func Timeout(d time.Duration) echo.MiddlewareFunc {
+	return func(n echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) (err error) {
+			ctx, cancel := context.WithTimeout(c.Request().Context(), d)
+			defer cancel()
+
+			c.SetRequest(c.Request().WithContext(ctx))
+			err = n(c)
+
+			if ctx.Err() == context.DeadlineExceeded && !c.Response().Committed {
+				return echo.NewHTTPError(http.StatusServiceUnavailable, "request timeout")
+			}
+
+			return err
+		}
+	}
+}
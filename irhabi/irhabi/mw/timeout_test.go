@@ -0,0 +1,37 @@
+package mw
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	e := echo.New()
+	h := Timeout(20 * time.Millisecond)(func(c echo.Context) error {
+		select {
+		case <-c.Request().Context().Done():
+			return c.Request().Context().Err()
+		case <-time.After(time.Second):
+			return c.String(http.StatusOK, "test")
+		}
+	})
+
+	req, _ := http.NewRequest(echo.GET, "/", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+	if he, ok := h(c).(*echo.HTTPError); assert.True(t, ok) {
+		assert.Equal(t, http.StatusServiceUnavailable, he.Code)
+	}
+
+	h = Timeout(time.Second)(func(c echo.Context) error {
+		return c.String(http.StatusOK, "test")
+	})
+
+	rec := httptest.NewRecorder()
+	assert.NoError(t, h(e.NewContext(req, rec)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,716 @@
+package irhabi
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alfatih/irhabi/validation"
+	"github.com/labstack/echo"
+)
+
+type (
+	// OpenAPI generating OpenAPI 3 document of the echo routes,
+	// request and response types of the routes are registered using Route.
+	OpenAPI struct {
+		Info    OpenAPIInfo
+		Servers []OpenAPIServer
+
+		mu     sync.RWMutex
+		routes map[string]*OpenAPIRoute
+	}
+
+	// OpenAPIRoute documentation of single route.
+	OpenAPIRoute struct {
+		summary     string
+		description string
+		tags        []string
+		request     reflect.Type
+		response    reflect.Type
+		query       bool
+	}
+
+	// OpenAPIDocument root of OpenAPI 3 document.
+	OpenAPIDocument struct {
+		OpenAPI    string                                  `json:"openapi"`
+		Info       OpenAPIInfo                             `json:"info"`
+		Servers    []OpenAPIServer                         `json:"servers,omitempty"`
+		Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+		Components OpenAPIComponents                       `json:"components"`
+	}
+
+	// OpenAPIInfo metadata of the api.
+	OpenAPIInfo struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version"`
+	}
+
+	// OpenAPIServer server of the api.
+	OpenAPIServer struct {
+		URL         string `json:"url"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// OpenAPIComponents reusable schemas of the document.
+	OpenAPIComponents struct {
+		Schemas map[string]*OpenAPISchema `json:"schemas,omitempty"`
+	}
+
+	// OpenAPIOperation single api operation of the path.
+	OpenAPIOperation struct {
+		Summary     string                      `json:"summary,omitempty"`
+		Description string                      `json:"description,omitempty"`
+		Tags        []string                    `json:"tags,omitempty"`
+		Parameters  []*OpenAPIParameter         `json:"parameters,omitempty"`
+		RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty"`
+		Responses   map[string]*OpenAPIResponse `json:"responses"`
+	}
+
+	// OpenAPIParameter path or query parameter of the operation.
+	OpenAPIParameter struct {
+		Name        string         `json:"name"`
+		In          string         `json:"in"`
+		Description string         `json:"description,omitempty"`
+		Required    bool           `json:"required,omitempty"`
+		Schema      *OpenAPISchema `json:"schema"`
+	}
+
+	// OpenAPIRequestBody request body of the operation.
+	OpenAPIRequestBody struct {
+		Required bool                        `json:"required"`
+		Content  map[string]OpenAPIMediaType `json:"content"`
+	}
+
+	// OpenAPIResponse response of the operation.
+	OpenAPIResponse struct {
+		Description string                      `json:"description"`
+		Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+	}
+
+	// OpenAPIMediaType schema of the content type.
+	OpenAPIMediaType struct {
+		Schema *OpenAPISchema `json:"schema"`
+	}
+
+	// OpenAPISchema json schema of the data.
+	OpenAPISchema struct {
+		Ref                  string                    `json:"$ref,omitempty"`
+		Type                 string                    `json:"type,omitempty"`
+		Format               string                    `json:"format,omitempty"`
+		Description          string                    `json:"description,omitempty"`
+		Nullable             bool                      `json:"nullable,omitempty"`
+		Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+		AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+		Items                *OpenAPISchema            `json:"items,omitempty"`
+		Required             []string                  `json:"required,omitempty"`
+		Enum                 []interface{}             `json:"enum,omitempty"`
+		Not                  *OpenAPISchema            `json:"not,omitempty"`
+		Pattern              string                    `json:"pattern,omitempty"`
+		Minimum              *float64                  `json:"minimum,omitempty"`
+		Maximum              *float64                  `json:"maximum,omitempty"`
+		ExclusiveMinimum     bool                      `json:"exclusiveMinimum,omitempty"`
+		ExclusiveMaximum     bool                      `json:"exclusiveMaximum,omitempty"`
+		MinLength            *int                      `json:"minLength,omitempty"`
+		MaxLength            *int                      `json:"maxLength,omitempty"`
+		MinItems             *int                      `json:"minItems,omitempty"`
+		MaxItems             *int                      `json:"maxItems,omitempty"`
+	}
+)
+
+// NewOpenAPI creates OpenAPI document generator with title and version of the api.
+func NewOpenAPI(title string, version string) *OpenAPI {
+	return &OpenAPI{
+		Info:   OpenAPIInfo{Title: title, Version: version},
+		routes: make(map[string]*OpenAPIRoute),
+	}
+}
+
+// Route returns documentation of the route, so the request
+// and response types can be registered, e.g:
+//
+//	doc.Route(echo.POST, "/user").Summary("Create user").Request(UserRequest{}).Response(model.User{})
+//	doc.Route(echo.GET, "/user").RequestQuery().Response([]*model.User{})
+func (a *OpenAPI) Route(method string, path string) *OpenAPIRoute {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	key := method + " " + path
+	if a.routes[key] == nil {
+		a.routes[key] = &OpenAPIRoute{}
+	}
+
+	return a.routes[key]
+}
+
+// Summary set short summary of the route.
+func (r *OpenAPIRoute) Summary(s string) *OpenAPIRoute {
+	r.summary = s
+	return r
+}
+
+// Description set long description of the route.
+func (r *OpenAPIRoute) Description(s string) *OpenAPIRoute {
+	r.description = s
+	return r
+}
+
+// Tags set tags to group the route.
+func (r *OpenAPIRoute) Tags(tags ...string) *OpenAPIRoute {
+	r.tags = tags
+	return r
+}
+
+// Request register type of the request body,
+// constraints of the fields are read from the valid tags.
+func (r *OpenAPIRoute) Request(v interface{}) *OpenAPIRoute {
+	r.request = reflect.TypeOf(v)
+	return r
+}
+
+// Response register type of the data in response format.
+func (r *OpenAPIRoute) Response(v interface{}) *OpenAPIRoute {
+	r.response = reflect.TypeOf(v)
+	return r
+}
+
+// RequestQuery document the query parameters read by Context.RequestQuery.
+func (r *OpenAPIRoute) RequestQuery() *OpenAPIRoute {
+	r.query = true
+	return r
+}
+
+// Document generate OpenAPI document of the echo routes.
+func (a *OpenAPI) Document(e *echo.Echo) *OpenAPIDocument {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	d := &OpenAPIDocument{
+		OpenAPI: "3.0.0",
+		Info:    a.Info,
+		Servers: a.Servers,
+		Paths:   make(map[string]map[string]*OpenAPIOperation),
+	}
+
+	sg := &schemaGenerator{schemas: make(map[string]*OpenAPISchema), types: make(map[reflect.Type]string)}
+	for _, rt := range e.Routes() {
+		if strings.HasSuffix(rt.Path, "*") {
+			continue
+		}
+
+		path, params := openAPIPath(rt.Path)
+		if d.Paths[path] == nil {
+			d.Paths[path] = make(map[string]*OpenAPIOperation)
+		}
+
+		r := a.routes[rt.Method+" "+rt.Path]
+		if r == nil {
+			r = &OpenAPIRoute{}
+		}
+
+		d.Paths[path][strings.ToLower(rt.Method)] = sg.operation(r, params)
+	}
+
+	d.Components.Schemas = sg.schemas
+
+	return d
+}
+
+// JSON returns handler that serving the document as json.
+func (a *OpenAPI) JSON(e *echo.Echo) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, a.Document(e))
+	}
+}
+
+// YAML returns handler that serving the document as yaml.
+func (a *OpenAPI) YAML(e *echo.Echo) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		b, err := a.Document(e).YAML()
+		if err != nil {
+			return err
+		}
+
+		return c.Blob(http.StatusOK, "application/x-yaml; charset=utf-8", b)
+	}
+}
+
+// YAML encode the document as yaml.
+func (d *OpenAPIDocument) YAML() ([]byte, error) {
+	b, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err = dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writeYAML(&buf, v, 0)
+
+	return buf.Bytes(), nil
+}
+
+// schemaGenerator generate json schema of go types,
+// named struct types are stored as components.
+type schemaGenerator struct {
+	schemas map[string]*OpenAPISchema
+	types   map[reflect.Type]string
+}
+
+// operation generate operation of the route.
+func (sg *schemaGenerator) operation(r *OpenAPIRoute, params []string) *OpenAPIOperation {
+	o := &OpenAPIOperation{
+		Summary:     r.summary,
+		Description: r.description,
+		Tags:        r.tags,
+		Responses:   make(map[string]*OpenAPIResponse),
+	}
+
+	for _, p := range params {
+		o.Parameters = append(o.Parameters, &OpenAPIParameter{Name: p, In: "path", Required: true, Schema: &OpenAPISchema{Type: "string"}})
+	}
+
+	if r.query {
+		o.Parameters = append(o.Parameters, requestQueryParameters()...)
+	}
+
+	var data *OpenAPISchema
+	var total bool
+	if r.response != nil {
+		data = sg.schema(r.response)
+		total = deref(r.response).Kind() == reflect.Slice
+	}
+	o.Responses["200"] = jsonResponse(http.StatusText(http.StatusOK), responseSchema(data, total, false))
+
+	if r.request != nil {
+		o.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content: map[string]OpenAPIMediaType{
+				echo.MIMEApplicationJSON: {Schema: sg.schema(r.request)},
+			},
+		}
+
+		o.Responses["422"] = jsonResponse(http.StatusText(http.StatusUnprocessableEntity), responseSchema(nil, false, true))
+	}
+
+	o.Responses["default"] = jsonResponse("Error", responseSchema(nil, false, false))
+
+	return o
+}
+
+// schema generate json schema of the type.
+func (sg *schemaGenerator) schema(t reflect.Type) *OpenAPISchema {
+	var nullable bool
+	if t.Kind() == reflect.Ptr {
+		t, nullable = deref(t), true
+	}
+
+	s := &OpenAPISchema{Nullable: nullable}
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		s.Type, s.Format = "string", "date-time"
+		return s
+	case reflect.TypeOf(multipart.FileHeader{}):
+		s.Type, s.Format = "string", "binary"
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		s.Type = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		s.Type, s.Format = "integer", "int32"
+	case reflect.Int64, reflect.Uint64:
+		s.Type, s.Format = "integer", "int64"
+	case reflect.Float32:
+		s.Type, s.Format = "number", "float"
+	case reflect.Float64:
+		s.Type, s.Format = "number", "double"
+	case reflect.String:
+		s.Type = "string"
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s.Type, s.Format = "string", "byte"
+		} else {
+			s.Type, s.Items = "array", sg.schema(t.Elem())
+		}
+	case reflect.Map:
+		s.Type, s.AdditionalProperties = "object", sg.schema(t.Elem())
+	case reflect.Struct:
+		if t.Name() == "" {
+			return sg.object(t)
+		}
+
+		// $ref cannot have siblings, so the nullable is ignored.
+		return &OpenAPISchema{Ref: "#/components/schemas/" + sg.component(t)}
+	}
+
+	return s
+}
+
+// component register named struct type as component,
+// and returns name of the component.
+func (sg *schemaGenerator) component(t reflect.Type) string {
+	if n, ok := sg.types[t]; ok {
+		return n
+	}
+
+	n := t.Name()
+	if _, ok := sg.schemas[n]; ok {
+		n = strings.Replace(t.String(), ".", "_", -1)
+	}
+
+	// reserved before generated, so recursive type is referring to itself.
+	sg.types[t] = n
+	sg.schemas[n] = nil
+	sg.schemas[n] = sg.object(t)
+
+	return n
+}
+
+// object generate json schema of the struct fields,
+// constraints of the fields are read from the valid tags.
+func (sg *schemaGenerator) object(t reflect.Type) *OpenAPISchema {
+	s := &OpenAPISchema{Type: "object", Properties: make(map[string]*OpenAPISchema)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+
+		name := tagName(f.Tag.Get("json"))
+		if name == "-" {
+			continue
+		}
+
+		// embedded struct fields are promoted into the parent.
+		if name == "" && f.Anonymous && deref(f.Type).Kind() == reflect.Struct {
+			es := sg.object(deref(f.Type))
+			for k, v := range es.Properties {
+				s.Properties[k] = v
+			}
+			s.Required = append(s.Required, es.Required...)
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := sg.schema(f.Type)
+		if applyRules(fs, f.Tag.Get("valid")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = fs
+	}
+
+	return s
+}
+
+// applyRules map validation rules into schema constraints,
+// returns true if the field is required.
+func applyRules(s *OpenAPISchema, tag string) (required bool) {
+	if tag == "" || tag == "-" {
+		return false
+	}
+
+	// constraints of $ref schema cannot be applied.
+	if s.Ref != "" {
+		return strings.Contains("|"+tag+"|", "|required|")
+	}
+
+	for _, r := range strings.Split(tag, "|") {
+		p := strings.SplitN(r, ":", 2)
+		name, param := strings.TrimSpace(p[0]), ""
+		if len(p) > 1 {
+			param = strings.TrimSpace(p[1])
+		}
+
+		switch name {
+		case "required":
+			required = true
+		case "gte", "gt":
+			if n, err := strconv.ParseFloat(param, 64); err == nil {
+				s.setMin(n, name == "gt")
+			}
+		case "lte", "lt":
+			if n, err := strconv.ParseFloat(param, 64); err == nil {
+				s.setMax(n, name == "lt")
+			}
+		case "range":
+			if rp := strings.Split(param, ","); len(rp) == 2 {
+				min, e1 := strconv.ParseFloat(rp[0], 64)
+				max, e2 := strconv.ParseFloat(rp[1], 64)
+				if e1 == nil && e2 == nil {
+					s.setMin(min, false)
+					s.setMax(max, false)
+				}
+			}
+		case "in":
+			s.Enum = enumValues(s, param)
+		case "not_in":
+			s.Not = &OpenAPISchema{Enum: enumValues(s, param)}
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		case "numeric":
+			s.Pattern = validation.Float
+		case "alpha":
+			s.Pattern = validation.Alpha
+		case "alpha_num":
+			s.Pattern = validation.Alphanumeric
+		case "alpha_num_space":
+			s.Pattern = validation.AlphanumericSpace
+		case "alpha_space":
+			s.Pattern = validation.AlphaSpace
+		case "match":
+			s.Pattern = param
+		case "contains":
+			s.Pattern = regexp.QuoteMeta(param)
+		}
+	}
+
+	return required
+}
+
+// setMin set minimum value, length of string or number of items.
+func (s *OpenAPISchema) setMin(n float64, exclusive bool) {
+	switch s.Type {
+	case "string":
+		l := int(n)
+		if exclusive {
+			l++
+		}
+		s.MinLength = &l
+	case "array":
+		l := int(n)
+		if exclusive {
+			l++
+		}
+		s.MinItems = &l
+	default:
+		s.Minimum, s.ExclusiveMinimum = &n, exclusive
+	}
+}
+
+// setMax set maximum value, length of string or number of items.
+func (s *OpenAPISchema) setMax(n float64, exclusive bool) {
+	switch s.Type {
+	case "string":
+		l := int(n)
+		if exclusive {
+			l--
+		}
+		s.MaxLength = &l
+	case "array":
+		l := int(n)
+		if exclusive {
+			l--
+		}
+		s.MaxItems = &l
+	default:
+		s.Maximum, s.ExclusiveMaximum = &n, exclusive
+	}
+}
+
+// enumValues convert comma separated values into type of the schema.
+func enumValues(s *OpenAPISchema, param string) (values []interface{}) {
+	for _, v := range strings.Split(param, ",") {
+		if s.Type == "integer" || s.Type == "number" {
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				values = append(values, n)
+				continue
+			}
+		}
+
+		values = append(values, v)
+	}
+
+	return values
+}
+
+// requestQueryParameters returns query parameters
+// that read by orm.RequestQuery.
+func requestQueryParameters() []*OpenAPIParameter {
+	return []*OpenAPIParameter{
+		{Name: "page", In: "query", Description: "Page number, starting from 1.", Schema: &OpenAPISchema{Type: "integer"}},
+		{Name: "perpage", In: "query", Description: "Number of data per page.", Schema: &OpenAPISchema{Type: "integer"}},
+		{Name: "orderby", In: "query", Description: "Comma separated fields to sort, prefix with - for descending order e.g -created_at,name.", Schema: &OpenAPISchema{Type: "string"}},
+		{Name: "embeds", In: "query", Description: "Comma separated relations to be loaded e.g user,user.role.", Schema: &OpenAPISchema{Type: "string"}},
+		{Name: "fields", In: "query", Description: "Comma separated fields to be returned.", Schema: &OpenAPISchema{Type: "string"}},
+		{Name: "filter", In: "query", Description: "Filter expression, field:operator:value separated by comma and grouped by and(...), or(...) or not(...) e.g (status:eq:active,or(age:gt:30,group.id:in:1|2)).", Schema: &OpenAPISchema{Type: "string"}},
+		{Name: "q", In: "query", Description: "Search term, matched on the searchable fields of the model.", Schema: &OpenAPISchema{Type: "string"}},
+		{Name: "cursor", In: "query", Description: "Cursor of the next page on cursor pagination, from meta next_cursor.", Schema: &OpenAPISchema{Type: "string"}},
+		{Name: "before", In: "query", Description: "Cursor of the previous page on cursor pagination, from meta prev_cursor.", Schema: &OpenAPISchema{Type: "string"}},
+		{Name: "format", In: "query", Description: "Export format of the list, the response is a file of all rows instead of json.", Schema: &OpenAPISchema{Type: "string", Enum: []interface{}{ExportCSV, ExportXLSX, ExportNDJSON}}},
+		{Name: "conditions", In: "query", Description: "Filter conditions, field:value separated by %2C and groups separated by | e.g name:john%2Crole.id:1|Or.status:active.", Schema: &OpenAPISchema{Type: "string"}},
+	}
+}
+
+// responseSchema returns schema of response format with the data,
+// fail response has errors messages of the fields.
+func responseSchema(data *OpenAPISchema, total bool, errors bool) *OpenAPISchema {
+	s := &OpenAPISchema{
+		Type: "object",
+		Properties: map[string]*OpenAPISchema{
+			"status":  {Type: "string", Enum: []interface{}{HTTPResponseSuccess, HTTPResponseFail}},
+			"message": {Type: "string"},
+		},
+	}
+
+	if data != nil {
+		s.Properties["data"] = data
+	}
+
+	if total {
+		s.Properties["total"] = &OpenAPISchema{Type: "integer", Format: "int64"}
+	}
+
+	if errors {
+		s.Properties["errors"] = &OpenAPISchema{Type: "object", AdditionalProperties: &OpenAPISchema{Type: "string"}}
+	}
+
+	return s
+}
+
+// jsonResponse returns response of json content.
+func jsonResponse(desc string, s *OpenAPISchema) *OpenAPIResponse {
+	return &OpenAPIResponse{
+		Description: desc,
+		Content:     map[string]OpenAPIMediaType{echo.MIMEApplicationJSON: {Schema: s}},
+	}
+}
+
+// openAPIPath convert echo route path into OpenAPI path,
+// and returns name of the path parameters.
+func openAPIPath(path string) (string, []string) {
+	var params []string
+	seg := strings.Split(path, "/")
+	for i, s := range seg {
+		if strings.HasPrefix(s, ":") {
+			params = append(params, s[1:])
+			seg[i] = "{" + s[1:] + "}"
+		}
+	}
+
+	return strings.Join(seg, "/"), params
+}
+
+// deref returns element type of pointer type.
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+// yamlPlain pattern of the key that can be written without quotes.
+var yamlPlain = regexp.MustCompile(`^[A-Za-z_$/][A-Za-z0-9_$/.{}-]*$`)
+
+// writeYAML write the decoded json value as yaml block.
+func writeYAML(b *bytes.Buffer, v interface{}, indent int) {
+	pad := strings.Repeat(" ", indent)
+	switch x := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			key := k
+			if !yamlPlain.MatchString(k) {
+				key = strconv.Quote(k)
+			}
+
+			b.WriteString(pad + key + ":")
+			writeYAMLValue(b, x[k], indent)
+		}
+	case []interface{}:
+		for _, i := range x {
+			var ib bytes.Buffer
+			writeYAML(&ib, i, indent+2)
+			if isYAMLBlock(i) {
+				// first line of the item is written after the dash.
+				b.WriteString(pad + "- " + strings.TrimPrefix(ib.String(), pad+"  "))
+			} else {
+				b.WriteString(pad + "- " + ib.String())
+			}
+		}
+	default:
+		b.WriteString(yamlScalar(v) + "\n")
+	}
+}
+
+// writeYAMLValue write value of the map key.
+func writeYAMLValue(b *bytes.Buffer, v interface{}, indent int) {
+	if !isYAMLBlock(v) {
+		b.WriteString(" ")
+		writeYAML(b, yamlEmpty(v), indent)
+		return
+	}
+
+	b.WriteString("\n")
+	if _, ok := v.([]interface{}); ok {
+		writeYAML(b, v, indent)
+	} else {
+		writeYAML(b, v, indent+2)
+	}
+}
+
+// isYAMLBlock returns true if value is not empty map or list.
+func isYAMLBlock(v interface{}) bool {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		return len(x) > 0
+	case []interface{}:
+		return len(x) > 0
+	}
+
+	return false
+}
+
+// yamlEmpty replace empty map or list with flow style.
+func yamlEmpty(v interface{}) interface{} {
+	switch v.(type) {
+	case map[string]interface{}:
+		return json.Number("{}")
+	case []interface{}:
+		return json.Number("[]")
+	}
+
+	return v
+}
+
+// yamlScalar format scalar value.
+func yamlScalar(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(x)
+	case json.Number:
+		return x.String()
+	case string:
+		return strconv.Quote(x)
+	}
+
+	b, _ := json.Marshal(v)
+	return string(b)
+}
@@ -0,0 +1,113 @@
+package irhabi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+type apiUser struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Manager   *apiUser  `json:"manager,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Password  string    `json:"-"`
+}
+
+type apiUserRequest struct {
+	Name   string   `json:"name" valid:"required|gte:3|lte:50"`
+	Email  string   `json:"email" valid:"required|email"`
+	Age    int      `json:"age" valid:"gte:17"`
+	Role   string   `json:"role" valid:"in:admin,staff"`
+	Groups []string `json:"groups" valid:"gte:1"`
+}
+
+func TestOpenAPI(t *testing.T) {
+	handler := func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}
+
+	e := echo.New()
+	e.GET("/user", handler)
+	e.POST("/user", handler)
+	e.GET("/user/:id", handler)
+	e.GET("/static/*", handler)
+
+	doc := NewOpenAPI("Test API", "1.0")
+	doc.Route(echo.GET, "/user").Summary("List users").RequestQuery().Response([]*apiUser{})
+	doc.Route(echo.POST, "/user").Request(apiUserRequest{}).Response(apiUser{})
+	e.GET("/openapi.json", doc.JSON(e))
+	e.GET("/openapi.yaml", doc.YAML(e))
+
+	d := doc.Document(e)
+	assert.Equal(t, "3.0.0", d.OpenAPI)
+	assert.Equal(t, "Test API", d.Info.Title)
+	assert.NotContains(t, d.Paths, "/static/*")
+
+	list := d.Paths["/user"]["get"]
+	if assert.NotNil(t, list) {
+		assert.Equal(t, "List users", list.Summary)
+		if assert.Len(t, list.Parameters, 11) {
+			assert.Equal(t, "filter", list.Parameters[5].Name)
+			assert.Equal(t, "q", list.Parameters[6].Name)
+			assert.Equal(t, "cursor", list.Parameters[7].Name)
+			assert.Equal(t, "before", list.Parameters[8].Name)
+			assert.Equal(t, "format", list.Parameters[9].Name)
+		}
+		data := list.Responses["200"].Content[echo.MIMEApplicationJSON].Schema
+		assert.Equal(t, "array", data.Properties["data"].Type)
+		assert.Equal(t, "#/components/schemas/apiUser", data.Properties["data"].Items.Ref)
+		assert.Equal(t, "integer", data.Properties["total"].Type)
+	}
+
+	detail := d.Paths["/user/{id}"]["get"]
+	if assert.NotNil(t, detail) && assert.Len(t, detail.Parameters, 1) {
+		assert.Equal(t, "id", detail.Parameters[0].Name)
+		assert.Equal(t, "path", detail.Parameters[0].In)
+		assert.True(t, detail.Parameters[0].Required)
+	}
+
+	create := d.Paths["/user"]["post"]
+	if assert.NotNil(t, create) && assert.NotNil(t, create.RequestBody) {
+		req := create.RequestBody.Content[echo.MIMEApplicationJSON].Schema
+		assert.Equal(t, "#/components/schemas/apiUserRequest", req.Ref)
+		assert.Contains(t, create.Responses, "422")
+	}
+
+	user := d.Components.Schemas["apiUser"]
+	if assert.NotNil(t, user) {
+		assert.Equal(t, "#/components/schemas/apiUser", user.Properties["manager"].Ref)
+		assert.Equal(t, "date-time", user.Properties["created_at"].Format)
+		assert.NotContains(t, user.Properties, "Password")
+	}
+
+	ur := d.Components.Schemas["apiUserRequest"]
+	if assert.NotNil(t, ur) {
+		assert.Equal(t, []string{"name", "email"}, ur.Required)
+		assert.Equal(t, 3, *ur.Properties["name"].MinLength)
+		assert.Equal(t, 50, *ur.Properties["name"].MaxLength)
+		assert.Equal(t, "email", ur.Properties["email"].Format)
+		assert.Equal(t, float64(17), *ur.Properties["age"].Minimum)
+		assert.Equal(t, []interface{}{"admin", "staff"}, ur.Properties["role"].Enum)
+		assert.Equal(t, 1, *ur.Properties["groups"].MinItems)
+	}
+
+	req, _ := http.NewRequest(echo.GET, "/openapi.json", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.True(t, json.Valid(rec.Body.Bytes()))
+
+	req, _ = http.NewRequest(echo.GET, "/openapi.yaml", nil)
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "openapi: \"3.0.0\"\n")
+	assert.Contains(t, rec.Body.String(), "\n  /user/{id}:\n    get:\n      parameters:\n      - in: \"path\"\n        name: \"id\"\n")
+	assert.Contains(t, rec.Body.String(), "\n        \"200\":\n")
+}
@@ -0,0 +1,153 @@
+package irhabi
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alfatih/irhabi/common"
+)
+
+type (
+	// Meta pagination metadata of list responses.
+	Meta struct {
+		Page       int    `json:"page,omitempty"`
+		PerPage    int    `json:"perpage"`
+		Total      int64  `json:"total,omitempty"`
+		LastPage   int    `json:"last_page,omitempty"`
+		HasMore    bool   `json:"has_more"`
+		NextCursor string `json:"next_cursor,omitempty"`
+		PrevCursor string `json:"prev_cursor,omitempty"`
+	}
+
+	// Links navigation links of list responses.
+	Links struct {
+		First string `json:"first,omitempty"`
+		Prev  string `json:"prev,omitempty"`
+		Next  string `json:"next,omitempty"`
+		Last  string `json:"last,omitempty"`
+	}
+)
+
+// SetPage fill data with pagination meta and links into response formater.
+func (r *ResponseFormat) SetPage(d interface{}, m *Meta, l *Links) *ResponseFormat {
+	r.SetData(d, m.Total)
+	r.Meta = m
+	r.Links = l
+
+	return r
+}
+
+// Paginate set data with offset pagination meta and links, page and perpage
+// are read from the request query as RequestQuery does, e.g:
+//
+//	data, total, e := repository.GetUsers(ctx.RequestQuery())
+//	ctx.Paginate(data, total)
+func (c *Context) Paginate(data interface{}, total int64) {
+	perpage := c.RequestQuery().Limit
+	page := common.ToInt(c.QueryParam("page"))
+	if page < 1 {
+		page = 1
+	}
+
+	m := &Meta{Page: page, PerPage: perpage, Total: total, LastPage: 1}
+	if perpage > 0 && total > 0 {
+		m.LastPage = int((total + int64(perpage) - 1) / int64(perpage))
+	}
+	m.HasMore = page < m.LastPage
+
+	l := &Links{
+		First: c.pageURL("page", "1"),
+		Last:  c.pageURL("page", strconv.Itoa(m.LastPage)),
+	}
+
+	if page > 1 {
+		prev := page - 1
+		if prev > m.LastPage {
+			prev = m.LastPage
+		}
+		l.Prev = c.pageURL("page", strconv.Itoa(prev))
+	}
+
+	if m.HasMore {
+		l.Next = c.pageURL("page", strconv.Itoa(page+1))
+	}
+
+	c.setPage(data, m, l)
+}
+
+// PaginateCursor set data with cursor pagination meta and links,
+// next and prev are the cursor of the next and previous page,
+// empty cursor means there are no more page on the direction.
+// next link is using `cursor` query param and prev link is using `before`
+// query param, as RequestQuery read the cursor of QuerySeter.AllCursor.
+func (c *Context) PaginateCursor(data interface{}, next string, prev string) {
+	m := &Meta{
+		PerPage:    c.RequestQuery().Limit,
+		HasMore:    next != "",
+		NextCursor: next,
+		PrevCursor: prev,
+	}
+
+	l := &Links{First: c.pageURL("cursor", "", "before")}
+	if prev != "" {
+		l.Prev = c.pageURL("before", prev, "cursor")
+	}
+
+	if next != "" {
+		l.Next = c.pageURL("cursor", next, "before")
+	}
+
+	c.setPage(data, m, l)
+}
+
+// setPage set the data and the Link headers if enabled.
+func (c *Context) setPage(data interface{}, m *Meta, l *Links) {
+	c.responseFormat.SetPage(data, m, l)
+
+	if Config.PaginationLinkHeader {
+		if h := l.header(); h != "" {
+			c.Response().Header().Set("Link", h)
+		}
+	}
+}
+
+// pageURL returns url of current request with query param replaced,
+// empty value will remove the param, as well as the params on remove.
+func (c *Context) pageURL(param string, value string, remove ...string) string {
+	req := c.Request()
+	q := req.URL.Query()
+	for _, r := range remove {
+		q.Del(r)
+	}
+
+	if value == "" {
+		q.Del(param)
+	} else {
+		q.Set(param, value)
+	}
+
+	u := *req.URL
+	u.Scheme = c.Scheme()
+	u.Host = req.Host
+	u.RawQuery = q.Encode()
+
+	return u.String()
+}
+
+// header returns RFC 5988 Link header of the links.
+func (l *Links) header() string {
+	var h []string
+	for _, v := range []struct{ rel, url string }{
+		{"first", l.First},
+		{"prev", l.Prev},
+		{"next", l.Next},
+		{"last", l.Last},
+	} {
+		if v.url != "" {
+			h = append(h, fmt.Sprintf(`<%s>; rel="%s"`, v.url, v.rel))
+		}
+	}
+
+	return strings.Join(h, ", ")
+}
@@ -0,0 +1,107 @@
+package irhabi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alfatih/irhabi/orm"
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextPaginate(t *testing.T) {
+	header := Config.PaginationLinkHeader
+	defer func() {
+		Config.PaginationLinkHeader = header
+	}()
+	Config.PaginationLinkHeader = true
+
+	var cases = []struct {
+		query    string
+		total    int64
+		meta     Meta
+		prev     string
+		next     string
+		last     string
+		linkNext bool
+	}{
+		{"page=2&perpage=10&orderby=id", 35, Meta{Page: 2, PerPage: 10, Total: 35, LastPage: 4, HasMore: true},
+			"http://example.com/users?orderby=id&page=1&perpage=10", "http://example.com/users?orderby=id&page=3&perpage=10", "http://example.com/users?orderby=id&page=4&perpage=10", true},
+		{"page=4&perpage=10", 35, Meta{Page: 4, PerPage: 10, Total: 35, LastPage: 4},
+			"http://example.com/users?page=3&perpage=10", "", "http://example.com/users?page=4&perpage=10", false},
+		{"", 35, Meta{Page: 1, PerPage: -1, Total: 35, LastPage: 1},
+			"", "", "http://example.com/users?page=1", false},
+	}
+
+	for _, c := range cases {
+		rec := httptest.NewRecorder()
+		ctx, _ := fakeContext(echo.GET, "http://example.com/users?"+c.query, "", rec)
+		ctx.Request().Host = "example.com"
+		ctx.Paginate([]string{"a", "b"}, c.total)
+
+		if assert.NoError(t, ctx.Serve(nil)) {
+			var r struct {
+				Data  []string `json:"data"`
+				Total int64    `json:"total"`
+				Meta  Meta     `json:"meta"`
+				Links Links    `json:"links"`
+			}
+
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &r))
+			assert.Equal(t, c.total, r.Total)
+			assert.Equal(t, c.meta, r.Meta, c.query)
+			assert.Equal(t, c.prev, r.Links.Prev, c.query)
+			assert.Equal(t, c.next, r.Links.Next, c.query)
+			assert.Equal(t, c.last, r.Links.Last, c.query)
+			assert.Equal(t, c.linkNext, strings.Contains(rec.Header().Get("Link"), `rel="next"`), c.query)
+			assert.Contains(t, rec.Header().Get("Link"), `rel="first"`)
+		}
+	}
+}
+
+func TestContextPaginateCursor(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ctx, _ := fakeContext(echo.GET, "http://example.com/users?perpage=2&cursor=abc", "", rec)
+	ctx.Request().Host = "example.com"
+	ctx.PaginateCursor([]string{"a", "b"}, "def", "abc-prev")
+
+	if assert.NoError(t, ctx.Serve(nil)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"status":"success",
+			"data":["a","b"],
+			"meta":{"perpage":2,"has_more":true,"next_cursor":"def","prev_cursor":"abc-prev"},
+			"links":{
+				"first":"http://example.com/users?perpage=2",
+				"prev":"http://example.com/users?before=abc-prev&perpage=2",
+				"next":"http://example.com/users?cursor=def&perpage=2"
+			}
+		}`, rec.Body.String())
+		assert.Empty(t, rec.Header().Get("Link"))
+	}
+}
+
+func TestContextDataCursorPage(t *testing.T) {
+	rec := httptest.NewRecorder()
+	ctx, _ := fakeContext(echo.GET, "http://example.com/users?perpage=2&before=abc", "", rec)
+	ctx.Request().Host = "example.com"
+	ctx.Data(&orm.CursorPage{Data: []string{"a", "b"}, Next: "def", Prev: "abc-prev"}, 10)
+
+	if assert.NoError(t, ctx.Serve(nil)) {
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{
+			"status":"success",
+			"data":["a","b"],
+			"total":10,
+			"meta":{"perpage":2,"total":10,"has_more":true,"next_cursor":"def","prev_cursor":"abc-prev"},
+			"links":{
+				"first":"http://example.com/users?perpage=2",
+				"prev":"http://example.com/users?before=abc-prev&perpage=2",
+				"next":"http://example.com/users?cursor=def&perpage=2"
+			}
+		}`, rec.Body.String())
+	}
+}
@@ -0,0 +1,329 @@
+package irhabi
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/alfatih/irhabi/common"
+	"github.com/alfatih/irhabi/orm"
+	"github.com/labstack/echo"
+)
+
+// Actions of the resource endpoints.
+const (
+	ActionList   = "list"
+	ActionShow   = "show"
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Resource standard REST endpoints of the registered orm model,
+// the endpoints are registered into the route group by URLMapping:
+//
+//	GET    /     list with RequestQuery, paginated by page or cursor, or exported as Context.Export
+//	GET    /:id  show, embeds query param is applied, ETag is of ModelETag
+//	POST   /     create
+//	PUT    /:id  update, PATCH is also accepted, If-Match is checked with ModelETag
+//	DELETE /:id  delete, If-Match is checked with ModelETag
+//
+// Missing data returns ErrDataNotExists and used value of the unique fields
+// returns ErrDataExists, the hooks are optional e.g:
+//
+//	r := &irhabi.Resource{
+//		Model: new(model.User),
+//		Scope: func(c *irhabi.Context, qs orm.QuerySeter) orm.QuerySeter {
+//			return qs.Filter("company_id", companyID(c))
+//		},
+//	}
+//	r.URLMapping(e.Group("/user", irhabi.Authorized()))
+type Resource struct {
+	Model   interface{} // Pointer of the registered orm model
+	Name    string      // Name of the data on failure messages, default is the model name e.g user
+	Actions []string    // Registered actions, default is all actions
+	Cursor  bool        // List with cursor pagination instead of page pagination
+
+	// Authorize check permission of the action, data is nil on list,
+	// the request data on create and the stored data on show, update and delete.
+	Authorize func(c *Context, action string, data interface{}) error
+	// Scope limit the data of list, show, update and delete e.g by the tenant,
+	// the stored data of create and update should be in the scope.
+	Scope func(c *Context, qs orm.QuerySeter) orm.QuerySeter
+	// BeforeSave validate or fill the data before stored on create and update.
+	BeforeSave func(c *Context, action string, data interface{}) error
+	// Response shaping the data of the responses, data of list is pointer of the slice,
+	// the exports are not shaped.
+	Response func(c *Context, action string, data interface{}) interface{}
+
+	typ     reflect.Type
+	pk      string
+	uniques []string
+}
+
+// URLMapping register the endpoints of the allowed actions into the route group,
+// it will panic when the model is not registered.
+func (r *Resource) URLMapping(g *echo.Group) {
+	pk, uniques, ok := orm.ModelKeys(r.Model)
+	if !ok {
+		panic(fmt.Errorf("resource model `%T` not found, make sure it was registered with `orm.RegisterModel()`", r.Model))
+	}
+
+	r.typ = reflect.Indirect(reflect.ValueOf(r.Model)).Type()
+	r.pk, r.uniques = pk, uniques
+	if r.Name == "" {
+		r.Name = common.ToUnderscore(r.typ.Name())
+	}
+
+	if r.allowed(ActionList) {
+		g.GET("", r.handler(r.list))
+	}
+
+	if r.allowed(ActionShow) {
+		g.GET("/:id", r.handler(r.show))
+	}
+
+	if r.allowed(ActionCreate) {
+		g.POST("", r.handler(r.create))
+	}
+
+	if r.allowed(ActionUpdate) {
+		g.PUT("/:id", r.handler(r.update))
+		g.PATCH("/:id", r.handler(r.update))
+	}
+
+	if r.allowed(ActionDelete) {
+		g.DELETE("/:id", r.handler(r.delete))
+	}
+}
+
+// list serve the data with the request query.
+func (r *Resource) list(c *Context) (e error) {
+	if e = r.authorize(c, ActionList, nil); e != nil {
+		return c.Serve(e)
+	}
+
+	rq := c.RequestQuery()
+	qs := r.scope(c, rq.Apply(c.Orm().QueryTable(r.Model)))
+
+	data := reflect.New(reflect.SliceOf(reflect.PtrTo(r.typ))).Interface()
+	if c.ExportFormat() != "" {
+		return c.Export(qs, data)
+	}
+
+	if r.Cursor || rq.Cursor != "" || rq.Before != "" {
+		var page *orm.CursorPage
+		if page, e = qs.AllCursor(data); e == nil {
+			page.Data = r.response(c, ActionList, page.Data)
+			c.Data(page)
+		}
+
+		return c.Serve(e)
+	}
+
+	var total int64
+	if total, e = qs.Count(); e == nil {
+		if _, e = qs.All(data); e == nil {
+			c.Paginate(r.response(c, ActionList, data), total)
+		}
+	}
+
+	return c.Serve(e)
+}
+
+// show serve the data of the id.
+func (r *Resource) show(c *Context) (e error) {
+	var m interface{}
+	if m, e = r.read(c, ActionShow); e == nil {
+		c.SetETag(ModelETag(m), false)
+		c.Data(r.response(c, ActionShow, m))
+	}
+
+	return c.Serve(e)
+}
+
+// create store the data of the request body.
+func (r *Resource) create(c *Context) (e error) {
+	m := reflect.New(r.typ).Interface()
+	if e = c.Bind(m); e == nil {
+		// the primary key is always generated, not of the request body.
+		pk := reflect.ValueOf(m).Elem().FieldByName(r.pk)
+		pk.Set(reflect.Zero(pk.Type()))
+		if e = r.authorize(c, ActionCreate, m); e == nil {
+			if e = r.save(c, ActionCreate, m); e == nil {
+				c.Data(r.response(c, ActionCreate, m))
+			}
+		}
+	}
+
+	return c.Serve(e)
+}
+
+// update store the request body into the data of the id,
+// fields that not exists on the request body are keep as is.
+func (r *Resource) update(c *Context) (e error) {
+	var m interface{}
+	if m, e = r.read(c, ActionUpdate); e == nil {
+		// the primary key is always of the path param.
+		pk := reflect.ValueOf(m).Elem().FieldByName(r.pk)
+		id := pk.Interface()
+		if e = c.Bind(m); e == nil {
+			pk.Set(reflect.ValueOf(id))
+			if e = r.save(c, ActionUpdate, m); e == nil {
+				c.SetETag(ModelETag(m), false)
+				c.Data(r.response(c, ActionUpdate, m))
+			}
+		}
+	}
+
+	return c.Serve(e)
+}
+
+// delete remove the data of the id.
+func (r *Resource) delete(c *Context) (e error) {
+	var m interface{}
+	if m, e = r.read(c, ActionDelete); e == nil {
+		_, e = c.Orm().Delete(m)
+	}
+
+	return c.Serve(e)
+}
+
+// read returns the scoped and authorized data of the id param.
+func (r *Resource) read(c *Context, action string) (interface{}, error) {
+	m := reflect.New(r.typ)
+	pk := m.Elem().FieldByName(r.pk)
+	if setField(pk, c.Param("id")) != nil {
+		return nil, r.notExists()
+	}
+
+	qs := r.scope(c, c.Orm().QueryTable(r.Model)).Filter(r.pk, pk.Interface())
+	if rq := c.RequestQuery(); action == ActionShow && len(rq.Embeds) > 0 {
+		// embeds are limited by the query rules of the model as on list.
+		if e := rq.Validate(r.Model); e != nil {
+			return nil, e
+		}
+
+		qs = qs.RelatedSel(rq.GetJoin()...)
+	}
+
+	if e := qs.One(m.Interface()); e == orm.ErrNoRows {
+		return nil, r.notExists()
+	} else if e != nil {
+		return nil, e
+	}
+
+	if e := r.authorize(c, action, m.Interface()); e != nil {
+		return nil, e
+	}
+
+	// lost updates, the data should not changed since the client read it.
+	if action != ActionShow {
+		if e := c.IfMatch(ModelETag(m.Interface())); e != nil {
+			return nil, e
+		}
+	}
+
+	return m.Interface(), nil
+}
+
+// save validate the unique fields and store the data in a transaction,
+// stored data that out of the Scope is rolled back with forbidden error
+// e.g the request body changing the tenant of the data.
+func (r *Resource) save(c *Context, action string, m interface{}) (e error) {
+	if r.BeforeSave != nil {
+		if e = r.BeforeSave(c, action, m); e != nil {
+			return
+		}
+	}
+
+	o := c.Orm()
+	if e = o.Begin(); e != nil {
+		return
+	}
+
+	defer func() {
+		if e != nil {
+			o.Rollback()
+		} else {
+			e = o.Commit()
+		}
+	}()
+
+	ind := reflect.ValueOf(m).Elem()
+	pk := ind.FieldByName(r.pk).Interface()
+	for _, name := range r.uniques {
+		f := ind.FieldByName(name)
+		if f.IsZero() {
+			continue
+		}
+
+		if o.QueryTable(r.Model).Filter(name, f.Interface()).Exclude(r.pk, pk).Exist() {
+			sf, _ := ind.Type().FieldByName(name)
+			field := fieldName(sf, "form")
+			return ErrDataExists(field, fmt.Sprintf("The %s is already exists.", field))
+		}
+	}
+
+	if action == ActionCreate {
+		_, e = o.Insert(m)
+	} else {
+		_, e = o.Update(m)
+	}
+
+	if e == nil && r.Scope != nil {
+		if !r.scope(c, o.QueryTable(r.Model)).Filter(r.pk, ind.FieldByName(r.pk).Interface()).Exist() {
+			e = echo.ErrForbidden
+		}
+	}
+
+	return
+}
+
+// notExists returns error of the data that not exists.
+func (r *Resource) notExists() error {
+	return ErrDataNotExists("id", fmt.Sprintf("The %s is not exists.", r.Name))
+}
+
+// authorize call the Authorize hook if any.
+func (r *Resource) authorize(c *Context, action string, data interface{}) error {
+	if r.Authorize == nil {
+		return nil
+	}
+
+	return r.Authorize(c, action, data)
+}
+
+// scope call the Scope hook if any.
+func (r *Resource) scope(c *Context, qs orm.QuerySeter) orm.QuerySeter {
+	if r.Scope == nil {
+		return qs
+	}
+
+	return r.Scope(c, qs)
+}
+
+// response call the Response hook if any.
+func (r *Resource) response(c *Context, action string, data interface{}) interface{} {
+	if r.Response == nil {
+		return data
+	}
+
+	return r.Response(c, action, data)
+}
+
+// allowed returns true when the action is registered.
+func (r *Resource) allowed(action string) bool {
+	return len(r.Actions) == 0 || common.Contains(r.Actions, action)
+}
+
+// handler returns echo handler of the resource handler.
+func (r *Resource) handler(fn func(c *Context) error) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx, ok := c.(*Context)
+		if !ok {
+			ctx = NewContext(c)
+		}
+
+		return fn(ctx)
+	}
+}
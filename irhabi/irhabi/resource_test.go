@@ -0,0 +1,198 @@
+package irhabi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alfatih/irhabi/common"
+	"github.com/alfatih/irhabi/orm"
+	"github.com/labstack/echo"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+type resourceUser struct {
+	ID      orm.ID `orm:"column(id);auto" json:"id"`
+	Name    string `orm:"column(name)" json:"name" valid:"required"`
+	Email   string `orm:"column(email);unique" json:"email" valid:"required|email"`
+	Company int64  `orm:"column(company)" json:"-"`
+}
+
+// resourceGroup company of the group is writable by the request body.
+type resourceGroup struct {
+	ID      orm.ID `orm:"column(id);auto" json:"id"`
+	Name    string `orm:"column(name)" json:"name"`
+	Company int64  `orm:"column(company)" json:"company"`
+}
+
+func (m *resourceGroup) QueryRules() orm.QueryRules {
+	return orm.QueryRules{Sorts: []string{"id"}}
+}
+
+func init() {
+	orm.RegisterModel(new(resourceUser), new(resourceGroup))
+	orm.RegisterDataBase("default", "sqlite3", "file::memory:?cache=shared", 1, 1)
+}
+
+func resourceRequest(e *echo.Echo, method string, path string, body string) (int, map[string]interface{}) {
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	var r map[string]interface{}
+	json.Unmarshal(rec.Body.Bytes(), &r)
+
+	return rec.Code, r
+}
+
+func TestResource(t *testing.T) {
+	if !assert.NoError(t, orm.RunSyncdb("default", true, false)) {
+		return
+	}
+
+	// data of other company that never visible.
+	other := &resourceUser{Name: "other", Email: "other@qasico.com", Company: 2}
+	orm.NewOrm().Insert(other)
+
+	r := &Resource{
+		Model: new(resourceUser),
+		Authorize: func(c *Context, action string, data interface{}) error {
+			if u, ok := data.(*resourceUser); ok && action == ActionDelete && u.Name == "admin" {
+				return echo.ErrForbidden
+			}
+			return nil
+		},
+		Scope: func(c *Context, qs orm.QuerySeter) orm.QuerySeter {
+			return qs.Filter("company", 1)
+		},
+		BeforeSave: func(c *Context, action string, data interface{}) error {
+			data.(*resourceUser).Company = 1
+			return nil
+		},
+		Response: func(c *Context, action string, data interface{}) interface{} {
+			if action == ActionShow {
+				return map[string]interface{}{"name": data.(*resourceUser).Name}
+			}
+			return data
+		},
+	}
+
+	e := New()
+	r.URLMapping(e.Group("/user"))
+
+	code, res := resourceRequest(e, echo.POST, "/user", `{"name":"admin","email":"admin@qasico.com"}`)
+	assert.Equal(t, http.StatusOK, code)
+	id := res["data"].(map[string]interface{})["id"].(string)
+	assert.Len(t, id, 22)
+
+	code, res = resourceRequest(e, echo.POST, "/user", `{"name":"jon","email":"admin@qasico.com"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, map[string]interface{}{"email": "The email is already exists."}, res["errors"])
+
+	code, _ = resourceRequest(e, echo.POST, "/user", `{"name":"jon"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, res = resourceRequest(e, echo.POST, "/user", `{"name":"jon","email":"jon@qasico.com"}`)
+	assert.Equal(t, http.StatusOK, code)
+	jon := res["data"].(map[string]interface{})["id"].(string)
+
+	code, res = resourceRequest(e, echo.GET, "/user?orderby=-id&perpage=1", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(2), res["total"])
+	assert.Equal(t, "jon", res["data"].([]interface{})[0].(map[string]interface{})["name"])
+
+	code, res = resourceRequest(e, echo.GET, "/user/"+id, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]interface{}{"name": "admin"}, res["data"])
+
+	for _, path := range []string{"/user/" + common.EncodeID(int64(other.ID)), "/user/1", "/user/" + jon[:21] + "x"} {
+		code, res = resourceRequest(e, echo.GET, path, "")
+		assert.Equal(t, http.StatusUnprocessableEntity, code, path)
+		assert.Equal(t, map[string]interface{}{"id": "The resource_user is not exists."}, res["errors"], path)
+	}
+
+	code, res = resourceRequest(e, echo.PATCH, "/user/"+jon, `{"id":"`+id+`","name":"jon snow"}`)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]interface{}{"id": jon, "name": "jon snow", "email": "jon@qasico.com"}, res["data"])
+
+	code, res = resourceRequest(e, echo.PUT, "/user/"+jon, `{"email":"admin@qasico.com"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+
+	code, _ = resourceRequest(e, echo.DELETE, "/user/"+id, "")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	code, _ = resourceRequest(e, echo.DELETE, "/user/"+jon, "")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, res = resourceRequest(e, echo.GET, "/user", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(1), res["total"])
+}
+
+func TestResourceScope(t *testing.T) {
+	if !assert.NoError(t, orm.RunSyncdb("default", false, false)) {
+		return
+	}
+
+	e := New()
+	r := &Resource{
+		Model: new(resourceGroup),
+		Scope: func(c *Context, qs orm.QuerySeter) orm.QuerySeter {
+			return qs.Filter("company", 1)
+		},
+	}
+	r.URLMapping(e.Group("/group"))
+
+	other := &resourceGroup{Name: "other", Company: 2}
+	orm.NewOrm().Insert(other)
+	oid := common.EncodeID(int64(other.ID))
+
+	// primary key of the request body is ignored.
+	code, res := resourceRequest(e, echo.POST, "/group", `{"id":"`+oid+`","name":"admins","company":1}`)
+	assert.Equal(t, http.StatusOK, code)
+	id := res["data"].(map[string]interface{})["id"].(string)
+	assert.NotEqual(t, oid, id)
+	assert.True(t, orm.NewOrm().QueryTable(r.Model).Filter("id", other.ID).Filter("name", "other").Exist())
+
+	// data out of the scope is not stored.
+	code, _ = resourceRequest(e, echo.POST, "/group", `{"name":"users","company":2}`)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.False(t, orm.NewOrm().QueryTable(r.Model).Filter("name", "users").Exist())
+
+	code, _ = resourceRequest(e, echo.PATCH, "/group/"+id, `{"company":2}`)
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.True(t, orm.NewOrm().QueryTable(r.Model).Filter("name", "admins").Filter("company", 1).Exist())
+
+	// embeds of show are validated with the query rules.
+	code, res = resourceRequest(e, echo.GET, "/group/"+id+"?embeds=company", "")
+	assert.Equal(t, http.StatusUnprocessableEntity, code)
+	assert.Equal(t, map[string]interface{}{"embeds.company": "The company field is not embeddable."}, res["errors"])
+
+	code, _ = resourceRequest(e, echo.GET, "/group/"+id, "")
+	assert.Equal(t, http.StatusOK, code)
+}
+
+func TestResourceActions(t *testing.T) {
+	if !assert.NoError(t, orm.RunSyncdb("default", false, false)) {
+		return
+	}
+
+	e := New()
+	r := &Resource{Model: new(resourceUser), Actions: []string{ActionList, ActionShow}, Cursor: true}
+	r.URLMapping(e.Group("/user"))
+
+	code, res := resourceRequest(e, echo.GET, "/user?perpage=1", "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.NotNil(t, res["meta"])
+
+	code, _ = resourceRequest(e, echo.POST, "/user", `{"name":"jon","email":"jon@qasico.com"}`)
+	assert.NotEqual(t, http.StatusOK, code)
+
+	assert.Panics(t, func() {
+		(&Resource{Model: new(apiUser)}).URLMapping(e.Group("/api"))
+	})
+}
@@ -1,11 +1,10 @@
 package irhabi
 
 import (
+	"encoding/json"
 	"net/http"
 
-	"github.com/alfatih/irhabi/orm"
 	"github.com/alfatih/irhabi/validation"
-	"github.com/labstack/echo"
 )
 
 const (
@@ -14,22 +13,41 @@ const (
 
 	// HTTPResponseFail default status when responses has an errors.
 	HTTPResponseFail = "fail"
+
+	// ErrorFormatLegacy errors of responses are message of each field.
+	ErrorFormatLegacy = "legacy"
+
+	// ErrorFormatStructured errors of responses are code, params and message of each field.
+	ErrorFormatStructured = "structured"
 )
 
 // ResponseFormat is standart response formater of the applicatin.
 type ResponseFormat struct {
-	Code    int               `json:"-"`
-	Status  string            `json:"status,omitempty"`
-	Message interface{}       `json:"message,omitempty"`
-	Data    interface{}       `json:"data,omitempty"`
-	Total   int64             `json:"total,omitempty"`
-	Errors  map[string]string `json:"errors,omitempty"`
+	Code      int               `json:"-"`
+	Status    string            `json:"status,omitempty"`
+	ErrorCode string            `json:"code,omitempty"`
+	Message   interface{}       `json:"message,omitempty"`
+	Data      interface{}       `json:"data,omitempty"`
+	Total     int64             `json:"total,omitempty"`
+	Meta      *Meta             `json:"meta,omitempty"`
+	Links     *Links            `json:"links,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+
+	// Failures code and params of the errors, presented as errors on structured format.
+	Failures map[string]validation.Failure `json:"-"`
+
+	// ErrorFormat format of the errors, legacy or structured.
+	ErrorFormat string `json:"-"`
+
+	// Locale of the error messages catalog e.g id, empty is keeping the messages.
+	Locale string `json:"-"`
 }
 
 // NewResponse return new instances of response formater.
 func NewResponse() *ResponseFormat {
 	return &ResponseFormat{
-		Status: HTTPResponseSuccess,
+		Status:      HTTPResponseSuccess,
+		ErrorFormat: Config.ErrorFormat,
 	}
 }
 
@@ -46,64 +64,66 @@ func (r *ResponseFormat) SetData(d interface{}, t ...int64) *ResponseFormat {
 	return r
 }
 
-// SetError set an error into response formater.
+// SetError set an error into response formater,
+// status code, error code and message are resolved from DefaultErrorRegistry,
+// failure messages are localized with the catalog of Locale.
 func (r *ResponseFormat) SetError(err error) *ResponseFormat {
-	r.Code = http.StatusBadRequest
+	m, target := DefaultErrorRegistry.Lookup(err)
+
+	r.Code = m.Status
 	r.Status = HTTPResponseFail
-	r.Message = err.Error()
-
-	// Check error based on type
-	if he, ok := err.(*echo.HTTPError); ok {
-		// Error cause of http failure should return status as is the errors
-		// using standart http code.
-		r.Code = he.Code
-		r.Message = http.StatusText(r.Code)
-
-		// if debug mode error messages will
-		// send as response message.
-		if IsDebug() {
-			r.Message = he.Message
-		}
-	} else if o, ok := err.(*validation.Output); ok {
-		// Error cause of validation failure should return
-		// status 422 and returning all failure messages as errors.
-		r.Code = http.StatusUnprocessableEntity
-		r.Errors = o.Messages()
-		r.Message = http.StatusText(r.Code)
-	} else if oe, ok := err.(*orm.OrmError); ok {
-		// Error cause of error from databases.
-		// this should be treaten as bad requests.
-		// but for debuging we need to know what the
-		// error messages.
-		r.Code = http.StatusBadRequest
-		r.Message = http.StatusText(r.Code)
-
-		// if debug mode error messages will
-		// send as response message.
-		if IsDebug() {
-			r.Message = oe.Message
+	r.ErrorCode = m.Code
+	r.Message = m.message(target)
+
+	if m.Failures != nil {
+		r.Failures = m.Failures(target)
+		r.Errors = make(map[string]string, len(r.Failures))
+		for k, f := range r.Failures {
+			if r.Locale != "" {
+				f.Message = f.Localize(r.Locale)
+				r.Failures[k] = f
+			}
+			r.Errors[k] = f.Message
 		}
-	} else if ne, ok := err.(*DataNotExistsError); ok {
-		// Error cause of data not exists or invalid
-		// threated as Unprocessable Entity with code 422.
-		r.Code = http.StatusUnprocessableEntity
-		r.Errors = ne.Errors
-		r.Message = http.StatusText(r.Code)
-	} else if ne, ok := err.(*DataDuplicateError); ok {
-		// Error cause of data not exists or invalid
-		// threated as Unprocessable Entity with code 422.
-		r.Code = http.StatusUnprocessableEntity
-		r.Errors = ne.Errors
-		r.Message = http.StatusText(r.Code)
+	} else if m.Errors != nil {
+		r.Errors = m.Errors(target)
 	}
 
 	return r
 }
 
+// MarshalJSON makes errors presented as code, params and message on structured format,
+// errors without failure are presented with invalid code.
+func (r ResponseFormat) MarshalJSON() ([]byte, error) {
+	type format ResponseFormat
+	if r.ErrorFormat != ErrorFormatStructured || len(r.Errors) == 0 {
+		return json.Marshal((*format)(&r))
+	}
+
+	fs := make(map[string]validation.Failure, len(r.Errors))
+	for k, m := range r.Errors {
+		f, ok := r.Failures[k]
+		if !ok {
+			f.Code = "invalid"
+		}
+		f.Message = m
+		fs[k] = f
+	}
+
+	return json.Marshal(&struct {
+		*format
+		Errors map[string]validation.Failure `json:"errors"`
+	}{(*format)(&r), fs})
+}
+
 // reset all data in response formater
 func (r *ResponseFormat) reset() {
 	r.Data = nil
 	r.Errors = nil
+	r.Failures = nil
+	r.ErrorCode = ""
 	r.Message = nil
 	r.Total = 0
+	r.Meta = nil
+	r.Links = nil
 }
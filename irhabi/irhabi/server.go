@@ -0,0 +1,168 @@
+package irhabi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/alfatih/irhabi/common/log"
+	"github.com/alfatih/irhabi/orm"
+	"github.com/labstack/echo"
+)
+
+// gracefulEnv environment variable that marking the process
+// is restarted and inheriting listener socket from the parent.
+const gracefulEnv = "IRHABI_GRACEFUL"
+
+var (
+	shutdownMu    sync.Mutex
+	shutdownHooks []func()
+)
+
+// OnShutdown register function that will be called after the server
+// stopped and in-flight requests has been finished, hooks are called
+// in reverse order of registration, before database connections are closed
+// and the loggers are flushed. e.g stopping toolbox tasks:
+//
+//	irhabi.OnShutdown(toolbox.StopTask)
+func OnShutdown(fn func()) {
+	shutdownMu.Lock()
+	defer shutdownMu.Unlock()
+
+	shutdownHooks = append(shutdownHooks, fn)
+}
+
+// Serve starting echo servers and block until it stopped by SIGINT or SIGTERM,
+// in-flight requests are given time to finish as long as Config.ShutdownTimeout.
+// When Config.GracefulRestart is enabled, SIGHUP will start new process
+// that inheriting the listener socket and the new process will stop this process
+// once it ready, the same way as beego/grace does for net/http servers.
+func Serve(e *echo.Echo) (err error) {
+	if e.Listener, err = listen(Config.Host); err != nil {
+		return err
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- e.Start(Config.Host)
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(sig)
+
+	// we are the restarted process, so the parent
+	// should stop accepting new requests.
+	if os.Getenv(gracefulEnv) != "" {
+		if p, e := os.FindProcess(os.Getppid()); e == nil {
+			p.Signal(syscall.SIGTERM)
+		}
+	}
+
+	for {
+		select {
+		case err = <-errc:
+			if err == http.ErrServerClosed {
+				err = nil
+			}
+
+			runShutdownHooks()
+			return err
+		case s := <-sig:
+			if s == syscall.SIGHUP {
+				if Config.GracefulRestart {
+					log.Info("received %v, restarting server", s)
+					if err = restart(e.Listener); err != nil {
+						log.Error(err)
+					}
+				}
+				continue
+			}
+
+			log.Info("received %v, shutting down server", s)
+			return Shutdown(e)
+		}
+	}
+}
+
+// Shutdown stop the server from accepting new requests, waiting in-flight
+// requests to finish until Config.ShutdownTimeout and then calling the shutdown hooks.
+// Readiness is failing as soon as it called, and the requests are still accepted
+// for Config.ShutdownDelay so the load balancer has time to remove the instance.
+func Shutdown(e *echo.Echo) error {
+	atomic.StoreInt32(&shuttingDown, 1)
+	time.Sleep(Config.ShutdownDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), Config.ShutdownTimeout)
+	defer cancel()
+
+	err := e.Shutdown(ctx)
+	runShutdownHooks()
+
+	return err
+}
+
+// runShutdownHooks calling registered shutdown hooks, closing database
+// connections and flushing the loggers, the hooks only called once.
+func runShutdownHooks() {
+	shutdownMu.Lock()
+	hooks := shutdownHooks
+	shutdownHooks = nil
+	shutdownMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		hooks[i]()
+	}
+
+	if err := orm.CloseDataBases(); err != nil {
+		log.Error(err)
+	}
+
+	if f, ok := log.Log.Out.(*os.File); ok {
+		f.Sync()
+	}
+}
+
+// listen creates listener of the address, or using
+// the listener socket inherited from the parent process.
+func listen(addr string) (net.Listener, error) {
+	if os.Getenv(gracefulEnv) != "" {
+		f := os.NewFile(3, "")
+		defer f.Close()
+
+		return net.FileListener(f)
+	}
+
+	return net.Listen("tcp", addr)
+}
+
+// restart starting new process with the same arguments
+// and passing the listener socket as file descriptor 3.
+func restart(l net.Listener) error {
+	tl, ok := l.(*net.TCPListener)
+	if !ok {
+		return fmt.Errorf("restart: listener %T cannot be inherited", l)
+	}
+
+	f, err := tl.File()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cmd := exec.Command(os.Args[0], os.Args[1:]...)
+	cmd.Env = append(os.Environ(), gracefulEnv+"=1")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = []*os.File{f}
+
+	return cmd.Start()
+}
@@ -0,0 +1,61 @@
+package irhabi
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeShutdown(t *testing.T) {
+	host, timeout := Config.Host, Config.ShutdownTimeout
+	defer func() {
+		Config.Host, Config.ShutdownTimeout = host, timeout
+	}()
+
+	Config.Host = "127.0.0.1:0"
+	Config.ShutdownTimeout = time.Second
+
+	var hooks []int
+	OnShutdown(func() { hooks = append(hooks, 1) })
+	OnShutdown(func() { hooks = append(hooks, 2) })
+
+	started := make(chan bool)
+	e := New()
+	e.GET("/slow", func(c echo.Context) error {
+		close(started)
+		time.Sleep(100 * time.Millisecond)
+		return c.String(http.StatusOK, "OK")
+	})
+
+	served := make(chan error)
+	go func() {
+		served <- Serve(e)
+	}()
+
+	for i := 0; i < 100 && e.Listener == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	responded := make(chan string)
+	go func() {
+		res, err := http.Get("http://" + e.Listener.Addr().String() + "/slow")
+		if assert.NoError(t, err) {
+			b, _ := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			responded <- string(b)
+		}
+	}()
+
+	<-started
+	assert.NoError(t, Shutdown(e))
+	assert.Equal(t, "OK", <-responded)
+	assert.NoError(t, <-served)
+	assert.Equal(t, []int{2, 1}, hooks)
+
+	_, err := http.Get("http://" + e.Listener.Addr().String() + "/slow")
+	assert.Error(t, err)
+}
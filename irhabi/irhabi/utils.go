@@ -8,43 +8,49 @@ import (
 	"github.com/alfatih/irhabi/common/log"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/labstack/echo"
-	"github.com/labstack/echo/middleware"
 )
 
-// Authorized returns a JSON Web Token (JWT) auth middleware.
+// Authorized returns a JSON Web Token (JWT) auth middleware
+// using the default jwt manager.
 //
 // For valid token, it sets the user in context and calls next handler.
-// For invalid token, it returns "401 - Unauthorized" error.
+// For invalid or revoked token, it returns "401 - Unauthorized" error.
 // For empty token, it returns "400 - Bad Request" error.
 //
 // See: https://jwt.io/introduction
-// See `JWTConfig.TokenLookup`
 func Authorized() echo.MiddlewareFunc {
-	c := middleware.DefaultJWTConfig
-	c.SigningKey = JwtKey()
-	return middleware.JWTWithConfig(c)
+	return Jwt().Middleware()
 }
 
-// JwtKey byte of jwt secret keys.
+// JwtKey byte of jwt secret keys of the default jwt manager.
 func JwtKey() []byte {
+	Jwt()
 	return []byte(Config.JwtSecret)
 }
 
 // JwtToken make an JWT token keys and values
-// the return will become a valid token with
-// a life time 72 hours from the time generated.
+// the return will become a valid token signed by default jwt manager
+// with a life time as configured from the time generated.
 func JwtToken(k string, v interface{}) (token string) {
-	// new instances jwt
-	jwts := jwt.New(jwt.SigningMethodHS256)
+	m := Jwt()
+	now := time.Now()
 
 	// Set claims
-	claims := jwts.Claims.(jwt.MapClaims)
-	claims[k] = v
-	claims["exp"] = time.Now().Add(time.Hour * 72).Unix()
+	claims := jwt.MapClaims{
+		k:     v,
+		"iat": now.Unix(),
+		"exp": now.Add(m.lifetime).Unix(),
+		"jti": jwtID(),
+		"typ": JwtTypeAccess,
+	}
+
+	if m.issuer != "" {
+		claims["iss"] = m.issuer
+	}
 
 	// Generate encoded token
 	var e error
-	if token, e = jwts.SignedString(JwtKey()); e != nil {
+	if token, e = m.sign(claims); e != nil {
 		panic(e)
 	}
 
@@ -133,6 +133,22 @@ func (d *Dialer) DialAndSend(m ...*Message) error {
 	return Send(s, m...)
 }
 
+// Ping dials and authenticates to the SMTP server and closes the connection.
+func (d *Dialer) Ping() error {
+	s, err := d.Dial()
+	if err != nil {
+		return err
+	}
+
+	return s.Close()
+}
+
+// Ping dials the SMTP server of Config, it can be registered as health check
+// e.g irhabi.AddHealthCheck("mailer", irhabi.HealthCheckFunc(mailer.Ping))
+func Ping() error {
+	return NewDialer().Ping()
+}
+
 type smtpSender struct {
 	smtpClient
 	d *Dialer
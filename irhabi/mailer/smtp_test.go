@@ -142,6 +142,32 @@ func TestDialerTimeout(t *testing.T) {
 	})
 }
 
+func TestDialerPing(t *testing.T) {
+	d := NewDialer()
+	testClient := &mockClient{
+		t:    t,
+		want: []string{"Extension STARTTLS", "StartTLS", "Extension AUTH", "Auth", "Quit"},
+		addr: addr(d.Host, d.Port),
+	}
+
+	netDialTimeout = func(network, address string, d time.Duration) (net.Conn, error) {
+		assert.Equal(t, testClient.addr, address)
+		return testConn, nil
+	}
+
+	smtpNewClient = func(conn net.Conn, host string) (smtpClient, error) {
+		return testClient, nil
+	}
+
+	assert.NoError(t, d.Ping())
+	assert.Equal(t, len(testClient.want), testClient.i)
+
+	netDialTimeout = func(network, address string, d time.Duration) (net.Conn, error) {
+		return nil, io.ErrUnexpectedEOF
+	}
+	assert.Equal(t, io.ErrUnexpectedEOF, d.Ping())
+}
+
 type mockClient struct {
 	t       *testing.T
 	i       int
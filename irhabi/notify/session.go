@@ -5,6 +5,8 @@
 package notify
 
 import (
+	"errors"
+
 	"git.qasico.com/cuxs/env"
 	"gopkg.in/mgo.v2"
 )
@@ -40,6 +42,19 @@ func session() (err error) {
 	return
 }
 
+// Ping check the mongo db server of the session is reachable,
+// it can be registered as health check e.g irhabi.AddHealthCheck("notify", irhabi.HealthCheckFunc(notify.Ping))
+func Ping() error {
+	if Session == nil {
+		return errors.New("notify: session is not started")
+	}
+
+	s := Session.Copy()
+	defer s.Close()
+
+	return s.Ping()
+}
+
 // collection instance from mgo, reading from config
 func collection() *mgo.Collection {
 	return Session.DB(Config.Database).C(Config.Collection)
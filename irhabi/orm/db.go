@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"time"
 )
@@ -716,6 +717,9 @@ func (d *dbBase) UpdateBatch(q dbQuerier, qs *querySet, mi *modelInfo, cond *Con
 	}
 
 	where, args := tables.getCondSQL(cond, false, tz)
+	if qs != nil {
+		tables.addSearchSQL(qs.search, &where, nil, &args, tz)
+	}
 
 	values = append(values, args...)
 
@@ -804,13 +808,17 @@ func (d *dbBase) DeleteBatch(q dbQuerier, qs *querySet, mi *modelInfo, cond *Con
 		tables.parseRelated(qs.related, qs.relDepth)
 	}
 
-	if cond == nil || cond.IsEmpty() {
+	searching := qs != nil && qs.search != nil && qs.search.term != "" && len(qs.search.fields) > 0
+	if (cond == nil || cond.IsEmpty()) && !searching {
 		panic(fmt.Errorf("delete operation cannot execute without condition"))
 	}
 
 	Q := d.ins.TableQuote()
 
 	where, args := tables.getCondSQL(cond, false, tz)
+	if searching {
+		tables.addSearchSQL(qs.search, &where, nil, &args, tz)
+	}
 	join := tables.getJoinSQL()
 
 	cols := fmt.Sprintf("T0.%s%s%s", Q, mi.fields.pk.column, Q)
@@ -911,9 +919,36 @@ func (d *dbBase) ReadBatch(q dbQuerier, qs *querySet, mi *modelInfo, cond *Condi
 
 	Q := d.ins.TableQuote()
 
+	// columns from the selected fields, relations of the fields
+	// are queried together when it is not included yet.
+	related := qs.related
+	var relCols map[string][]string
+	if len(cols) == 0 && len(qs.fields) > 0 {
+		var err error
+		if cols, relCols, err = selectFields(mi, qs.fields); err != nil {
+			return 0, err
+		}
+
+		if len(related) > 0 || qs.relDepth == 0 {
+			exists := make(map[string]bool)
+			for _, name := range related {
+				exists[name] = true
+			}
+
+			var names []string
+			for name := range relCols {
+				if !exists[name] {
+					names = append(names, name)
+				}
+			}
+			sort.Strings(names)
+			related = append(append([]string{}, related...), names...)
+		}
+	}
+
 	var tCols []string
 	if len(cols) > 0 {
-		hasRel := len(qs.related) > 0 || qs.relDepth > 0
+		hasRel := len(related) > 0 || qs.relDepth > 0
 		tCols = make([]string, 0, len(cols))
 		var maps map[string]bool
 		if hasRel {
@@ -947,19 +982,27 @@ func (d *dbBase) ReadBatch(q dbQuerier, qs *querySet, mi *modelInfo, cond *Condi
 	sels := fmt.Sprintf("T0.%s%s%s", Q, strings.Join(tCols, sep), Q)
 
 	tables := newDbTables(mi, d.ins)
-	tables.parseRelated(qs.related, qs.relDepth)
+	tables.parseRelated(related, qs.relDepth)
 
 	where, args := tables.getCondSQL(cond, false, tz)
 	groupBy := tables.getGroupSQL(qs.groups)
 	orderBy := tables.getOrderSQL(qs.orders)
+	tables.addSearchSQL(qs.search, &where, &orderBy, &args, tz)
 	limit := tables.getLimitSQL(mi, offset, rlimit)
 	join := tables.getJoinSQL()
 
+	relTCols := make(map[string][]string)
 	for _, tbl := range tables.tables {
 		if tbl.sel {
-			colsNum += len(tbl.mi.fields.dbcols)
+			rCols := tbl.mi.fields.dbcols
+			if c, ok := relCols[tbl.name]; ok {
+				rCols = c
+			}
+			relTCols[tbl.name] = rCols
+
+			colsNum += len(rCols)
 			sep := fmt.Sprintf("%s, %s.%s", Q, tbl.index, Q)
-			sels += fmt.Sprintf(", %s.%s%s%s", tbl.index, Q, strings.Join(tbl.mi.fields.dbcols, sep), Q)
+			sels += fmt.Sprintf(", %s.%s%s%s", tbl.index, Q, strings.Join(rCols, sep), Q)
 		}
 	}
 
@@ -1008,6 +1051,7 @@ func (d *dbBase) ReadBatch(q dbQuerier, qs *querySet, mi *modelInfo, cond *Condi
 			for _, tbl := range tables.tables {
 				// loop selected tables
 				if tbl.sel {
+					rCols := relTCols[tbl.name]
 					last := mind
 					names := ""
 					mmi := mi
@@ -1025,7 +1069,7 @@ func (d *dbBase) ReadBatch(q dbQuerier, qs *querySet, mi *modelInfo, cond *Condi
 							if last.Kind() != reflect.Invalid {
 								field = reflect.Indirect(last.FieldByIndex(fi.fieldIndex))
 								if field.IsValid() {
-									d.setColsValues(mmi, &field, mmi.fields.dbcols, trefs[:len(mmi.fields.dbcols)], tz)
+									d.setColsValues(mmi, &field, rCols, trefs[:len(rCols)], tz)
 									for _, fi := range mmi.fields.fieldsReverse {
 										if fi.inModel && fi.reverseFieldInfo.mi == lastm {
 											if fi.reverseFieldInfo != nil {
@@ -1043,7 +1087,7 @@ func (d *dbBase) ReadBatch(q dbQuerier, qs *querySet, mi *modelInfo, cond *Condi
 							cacheM[names] = mmi
 						}
 					}
-					trefs = trefs[len(mmi.fields.dbcols):]
+					trefs = trefs[len(rCols):]
 				}
 			}
 
@@ -1093,6 +1137,7 @@ func (d *dbBase) Count(q dbQuerier, qs *querySet, mi *modelInfo, cond *Condition
 	where, args := tables.getCondSQL(cond, false, tz)
 	groupBy := tables.getGroupSQL(qs.groups)
 	tables.getOrderSQL(qs.orders)
+	tables.addSearchSQL(qs.search, &where, nil, &args, tz)
 	join := tables.getJoinSQL()
 
 	Q := d.ins.TableQuote()
@@ -1615,6 +1660,7 @@ func (d *dbBase) ReadValues(q dbQuerier, qs *querySet, mi *modelInfo, cond *Cond
 	where, args := tables.getCondSQL(cond, false, tz)
 	groupBy := tables.getGroupSQL(qs.groups)
 	orderBy := tables.getOrderSQL(qs.orders)
+	tables.addSearchSQL(qs.search, &where, &orderBy, &args, tz)
 	limit := tables.getLimitSQL(mi, qs.offset, qs.limit)
 	join := tables.getJoinSQL()
 
@@ -1727,6 +1773,13 @@ func (d *dbBase) SupportUpdateJoin() bool {
 	return true
 }
 
+// full text search sql of the columns grouped by table,
+// returns match condition and relevance expression with ? of the search term,
+// empty means not supported.
+func (d *dbBase) FullTextSQL(columns [][]string) (string, string) {
+	return "", ""
+}
+
 func (d *dbBase) MaxLimit() uint64 {
 	return 18446744073709551615
 }
@@ -18,6 +18,7 @@ import (
 	"database/sql"
 	"fmt"
 	"reflect"
+	"sort"
 	"sync"
 	"time"
 )
@@ -97,6 +98,16 @@ func (ac *_dbCache) get(name string) (al *alias, ok bool) {
 	return
 }
 
+// remove database alias, returns the removed alias.
+func (ac *_dbCache) remove(name string) (al *alias, ok bool) {
+	ac.mux.Lock()
+	defer ac.mux.Unlock()
+	if al, ok = ac.cache[name]; ok {
+		delete(ac.cache, name)
+	}
+	return
+}
+
 // get default alias.
 func (ac *_dbCache) getDefault() (al *alias) {
 	al, _ = ac.get("default")
@@ -110,6 +121,7 @@ type alias struct {
 	DataSource   string
 	MaxIdleConns int
 	MaxOpenConns int
+	MaxLifetime  time.Duration
 	DB           *sql.DB
 	DbBaser      dbBaser
 	TZ           *time.Location
@@ -283,6 +295,25 @@ func SetMaxOpenConns(aliasName string, maxOpenConns int) {
 	}
 }
 
+// SetConnMaxLifetime Change the maximum amount of time a connection may be reused for *sql.DB,
+// use specify database alias name
+func SetConnMaxLifetime(aliasName string, d time.Duration) {
+	al := getDbAlias(aliasName)
+	al.MaxLifetime = d
+	al.DB.SetConnMaxLifetime(d)
+}
+
+// UnregisterDataBase close the database of the alias and remove the alias,
+// so the alias name can be registered again.
+func UnregisterDataBase(aliasName string) error {
+	al, ok := dataBaseCache.remove(aliasName)
+	if !ok {
+		return fmt.Errorf("DataBase alias name `%s` not registered", aliasName)
+	}
+
+	return al.DB.Close()
+}
+
 // GetDB Get *sql.DB from registered database by db alias name.
 // Use "default" as alias name if you not set.
 func GetDB(aliasNames ...string) (*sql.DB, error) {
@@ -298,3 +329,31 @@ func GetDB(aliasNames ...string) (*sql.DB, error) {
 	}
 	return nil, fmt.Errorf("DataBase of alias name `%s` not found", name)
 }
+
+// DataBaseAliases returns the name of all registered database alias.
+func DataBaseAliases() []string {
+	dataBaseCache.mux.RLock()
+	defer dataBaseCache.mux.RUnlock()
+
+	names := make([]string, 0, len(dataBaseCache.cache))
+	for name := range dataBaseCache.cache {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// CloseDataBases close *sql.DB of all registered database alias.
+func CloseDataBases() (err error) {
+	dataBaseCache.mux.RLock()
+	defer dataBaseCache.mux.RUnlock()
+
+	for _, al := range dataBaseCache.cache {
+		if e := al.DB.Close(); e != nil {
+			err = e
+		}
+	}
+
+	return err
+}
@@ -73,6 +73,18 @@ func (d *dbBaseMysql) OperatorSQL(operator string) string {
 	return mysqlOperators[operator]
 }
 
+// mysql full text search using MATCH ... AGAINST of each table,
+// the relevance is the sum of the matches.
+func (d *dbBaseMysql) FullTextSQL(columns [][]string) (string, string) {
+	matches := make([]string, 0, len(columns))
+	for _, group := range columns {
+		matches = append(matches, fmt.Sprintf("MATCH (%s) AGAINST (? IN NATURAL LANGUAGE MODE)", strings.Join(group, ", ")))
+	}
+
+	rank := fmt.Sprintf("(%s)", strings.Join(matches, " + "))
+	return rank + " > 0", rank
+}
+
 // get mysql table field types.
 func (d *dbBaseMysql) DbTypes() map[string]string {
 	return mysqlTypes
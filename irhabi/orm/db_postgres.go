@@ -17,6 +17,7 @@ package orm
 import (
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 // postgresql operators.
@@ -87,6 +88,20 @@ func (d *dbBasePostgres) SupportUpdateJoin() bool {
 	return false
 }
 
+// postgresql full text search using to_tsvector of all the columns,
+// ranked by ts_rank.
+func (d *dbBasePostgres) FullTextSQL(columns [][]string) (string, string) {
+	var cols []string
+	for _, group := range columns {
+		for _, col := range group {
+			cols = append(cols, fmt.Sprintf("coalesce(%s::text, '')", col))
+		}
+	}
+
+	doc := fmt.Sprintf("to_tsvector(%s)", strings.Join(cols, " || ' ' || "))
+	return fmt.Sprintf("%s @@ plainto_tsquery(?)", doc), fmt.Sprintf("ts_rank(%s, plainto_tsquery(?))", doc)
+}
+
 func (d *dbBasePostgres) MaxLimit() uint64 {
 	return 0
 }
@@ -390,6 +390,72 @@ func (t *dbTables) getCondSQL(cond *Condition, sub bool, tz *time.Location) (whe
 	return
 }
 
+// add search of the query set into where sql and order sql, the fields are
+// matched by full text search when enabled and supported by the database,
+// ordered by the relevance before the orders, otherwise by OR of icontains condition.
+// orderBy can be nil when the order is not needed e.g counting.
+func (t *dbTables) addSearchSQL(s *querySearch, where *string, orderBy *string, params *[]interface{}, tz *time.Location) {
+	if s == nil || s.term == "" || len(s.fields) == 0 {
+		return
+	}
+
+	var match, rank string
+	if s.fullText {
+		Q := t.base.TableQuote()
+		var columns [][]string
+		groups := make(map[string]int)
+		for _, field := range s.fields {
+			index, _, fi, suc := t.parseExprs(t.mi, strings.Split(field, ExprSep))
+			if !suc {
+				panic(fmt.Errorf("unknown field/column name `%s`", field))
+			}
+
+			col := fmt.Sprintf("%s.%s%s%s", index, Q, fi.column, Q)
+			if i, ok := groups[index]; ok {
+				columns[i] = append(columns[i], col)
+			} else {
+				groups[index] = len(columns)
+				columns = append(columns, []string{col})
+			}
+		}
+
+		match, rank = t.base.FullTextSQL(columns)
+	}
+
+	if match != "" {
+		for i := strings.Count(match, "?"); i > 0; i-- {
+			*params = append(*params, s.term)
+		}
+	} else {
+		c := NewCondition()
+		for _, field := range s.fields {
+			c = c.Or(field+ExprSep+"icontains", s.term)
+		}
+
+		var ps []interface{}
+		match, ps = t.getCondSQL(c, true, tz)
+		*params = append(*params, ps...)
+	}
+
+	if *where == "" {
+		*where = fmt.Sprintf("WHERE ( %s) ", match)
+	} else {
+		*where += fmt.Sprintf("AND ( %s) ", match)
+	}
+
+	if rank != "" && orderBy != nil {
+		for i := strings.Count(rank, "?"); i > 0; i-- {
+			*params = append(*params, s.term)
+		}
+
+		if *orderBy == "" {
+			*orderBy = fmt.Sprintf("ORDER BY %s DESC ", rank)
+		} else {
+			*orderBy = fmt.Sprintf("ORDER BY %s DESC, %s", rank, strings.TrimPrefix(*orderBy, "ORDER BY "))
+		}
+	}
+}
+
 // generate group sql.
 func (t *dbTables) getGroupSQL(groups []string) (groupSQL string) {
 	if len(groups) == 0 {
@@ -15,6 +15,7 @@
 package orm
 
 import (
+	"reflect"
 	"sync"
 )
 
@@ -97,3 +98,20 @@ func (mc *_modelCache) clean() {
 func ResetModelCache() {
 	modelCache.clean()
 }
+
+// ModelKeys returns struct field name of the primary key and the unique fields
+// of the registered model, ok is false when the model is not registered.
+func ModelKeys(md interface{}) (pk string, uniques []string, ok bool) {
+	mi, ok := modelCache.getByFullName(getFullName(reflect.Indirect(reflect.ValueOf(md)).Type()))
+	if !ok {
+		return "", nil, false
+	}
+
+	for _, fi := range mi.fields.fieldsDB {
+		if fi.unique {
+			uniques = append(uniques, fi.name)
+		}
+	}
+
+	return mi.fields.pk.name, uniques, true
+}
@@ -293,7 +293,7 @@ type Post struct {
 	Content string    `orm:"type(text)"`
 	Created time.Time `orm:"auto_now_add"`
 	Updated time.Time `orm:"auto_now"`
-	Tags    []*Tag    `orm:"rel(m2m);rel_through(github.com/astaxie/beego/orm.PostTags)"`
+	Tags    []*Tag    `orm:"rel(m2m);rel_through(github.com/alfatih/irhabi/orm.PostTags)"`
 }
 
 func (u *Post) TableIndex() [][]string {
@@ -351,7 +351,7 @@ type Group struct {
 type Permission struct {
 	ID     int `orm:"column(id)"`
 	Name   string
-	Groups []*Group `orm:"rel(m2m);rel_through(github.com/astaxie/beego/orm.GroupPermissions)"`
+	Groups []*Group `orm:"rel(m2m);rel_through(github.com/alfatih/irhabi/orm.GroupPermissions)"`
 }
 
 type GroupPermissions struct {
@@ -411,6 +411,20 @@ type PtrPk struct {
 	Positive bool
 }
 
+type MemberGroup struct {
+	ID   int    `orm:"column(id)" json:"id"`
+	Name string `orm:"size(30)" json:"name"`
+}
+
+type Member struct {
+	ID     int          `orm:"column(id)" json:"id"`
+	Name   string       `orm:"size(30)" json:"name"`
+	Age    int          `json:"age"`
+	Score  *int         `orm:"null" json:"score"`
+	Secret string       `orm:"size(30)" json:"-"`
+	Group  *MemberGroup `orm:"rel(fk);null" json:"group"`
+}
+
 var DBARGS = struct {
 	Driver string
 	Source string
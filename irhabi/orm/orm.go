@@ -52,11 +52,15 @@
 package orm
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
 	"reflect"
 	"time"
+
+	"github.com/Sirupsen/logrus"
+	"github.com/alfatih/irhabi/common/log"
 )
 
 // DebugQueries define the debug
@@ -88,9 +92,10 @@ type Params map[string]interface{}
 type ParamsList []interface{}
 
 type orm struct {
-	alias *alias
-	db    dbQuerier
-	isTx  bool
+	alias  *alias
+	db     dbQuerier
+	isTx   bool
+	fields logrus.Fields // fields of the query logs e.g request id
 }
 
 // OrmError represents an error that occurred while running orm.
@@ -460,8 +465,8 @@ func (o *orm) Using(name string) error {
 	}
 	if al, ok := dataBaseCache.get(name); ok {
 		o.alias = al
-		if Debug {
-			o.db = newDbQueryLog(al, al.DB)
+		if observed() {
+			o.db = newDbQueryLog(al, al.DB, o.fields)
 		} else {
 			o.db = al.DB
 		}
@@ -482,8 +487,8 @@ func (o *orm) Begin() error {
 		return err
 	}
 	o.isTx = true
-	if Debug {
-		o.db.(*dbQueryLog).SetDB(tx)
+	if ql, ok := o.db.(*dbQueryLog); ok {
+		ql.SetDB(tx)
 	} else {
 		o.db = tx
 	}
@@ -542,6 +547,19 @@ func NewOrm() Ormer {
 	return o
 }
 
+// NewOrmWithContext create new orm that the queries are logged with fields
+// of the log entry carried by the context e.g request id, see log.NewContext.
+func NewOrmWithContext(ctx context.Context) Ormer {
+	BootStrap() // execute only once
+
+	o := new(orm)
+	o.fields = log.FromContext(ctx).Data
+	if err := o.Using("default"); err != nil {
+		panic(err)
+	}
+	return o
+}
+
 // NewOrmWithDB create a new ormer object with specify *sql.DB for query
 func NewOrmWithDB(driverName, aliasName string, db *sql.DB) (Ormer, error) {
 	var al *alias
@@ -560,8 +578,8 @@ func NewOrmWithDB(driverName, aliasName string, db *sql.DB) (Ormer, error) {
 	o := new(orm)
 	o.alias = al
 
-	if Debug {
-		o.db = newDbQueryLog(o.alias, db)
+	if observed() {
+		o.db = newDbQueryLog(o.alias, db, nil)
 	} else {
 		o.db = db
 	}
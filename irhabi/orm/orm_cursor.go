@@ -0,0 +1,335 @@
+// Copyright 2016 PT. Qasico Teknologi Indonesia. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// CursorSecret key to sign the cursors of keyset pagination,
+// random by default so the cursors are only valid for the running process.
+var CursorSecret = randomSecret()
+
+type (
+	// CursorPage rows of keyset pagination with cursor of the next and
+	// previous page, empty cursor means there are no more page on the direction.
+	CursorPage struct {
+		Data interface{}
+		Next string
+		Prev string
+	}
+
+	// cursorToken payload of the cursor, values of the order fields of the row.
+	cursorToken struct {
+		Orders []string      `json:"o"`
+		Values []interface{} `json:"v"`
+	}
+)
+
+// After set cursor to query the rows after the row of the cursor.
+func (o querySet) After(cursor string) QuerySeter {
+	o.after, o.before = cursor, ""
+	return &o
+}
+
+// Before set cursor to query the rows before the row of the cursor.
+func (o querySet) Before(cursor string) QuerySeter {
+	o.after, o.before = "", cursor
+	return &o
+}
+
+// AllCursor query one page of keyset pagination into the slice container,
+// the page is ordered by the orders of the query set and the primary key.
+func (o *querySet) AllCursor(container interface{}, cols ...string) (*CursorPage, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+
+	slice := reflect.Indirect(reflect.ValueOf(container))
+	if slice.Kind() != reflect.Slice {
+		panic(fmt.Errorf("wrong object type `%T` for cursor pagination, need *[]*%s or *[]%s", container, o.mi.fullName, o.mi.fullName))
+	}
+
+	// rank of full text search is ordered before the orders of the keyset.
+	if o.search != nil && o.search.fullText {
+		return nil, &FieldsError{Errors: map[string]string{"q": "Full text search is not supported by cursor pagination."}}
+	}
+
+	orders, err := o.keysetOrders()
+	if err != nil {
+		return nil, err
+	}
+
+	limit := o.limit
+	if limit == 0 {
+		limit = int64(DefaultRowsLimit)
+	}
+
+	q := *o
+	q.orders = orders
+	q.offset = 0
+	if limit > 0 {
+		q.limit = limit + 1
+	}
+
+	// the cursor is encoded from the values of the orders,
+	// so they are always selected.
+	if len(cols) > 0 {
+		cols = append([]string{}, cols...)
+		for _, order := range orders {
+			fi, _ := o.mi.fields.GetByAny(strings.TrimPrefix(order, "-"))
+			if !containsString(cols, fi.column, fi.name) {
+				cols = append(cols, fi.column)
+			}
+		}
+	} else if len(q.fields) > 0 {
+		q.fields = append([]string{}, q.fields...)
+		for _, order := range orders {
+			fi, _ := o.mi.fields.GetByAny(strings.TrimPrefix(order, "-"))
+			q.fields = append(q.fields, fi.jsonName())
+		}
+	}
+
+	param, cursor := "cursor", o.after
+	if o.before != "" {
+		param, cursor = "before", o.before
+		q.orders = reverseOrders(orders)
+	}
+
+	if cursor != "" {
+		values, err := o.decodeCursor(cursor, orders)
+		if err != nil {
+			return nil, &FieldsError{Errors: map[string]string{param: "The cursor is invalid."}}
+		}
+
+		if q.cond == nil {
+			q.cond = NewCondition()
+		}
+		q.cond = q.cond.AndCond(keysetCond(q.orders, values))
+	}
+
+	if _, err = q.orm.alias.DbBaser.ReadBatch(q.orm.db, &q, q.mi, q.cond, container, q.orm.alias.TZ, cols); err != nil {
+		return nil, err
+	}
+
+	more := limit > 0 && int64(slice.Len()) > limit
+	if more {
+		slice.Set(slice.Slice(0, int(limit)))
+	}
+
+	if o.before != "" {
+		swap := reflect.Swapper(slice.Interface())
+		for i, j := 0, slice.Len()-1; i < j; i, j = i+1, j-1 {
+			swap(i, j)
+		}
+	}
+
+	page := &CursorPage{Data: container}
+	if n := slice.Len(); n > 0 {
+		first, last := o.encodeCursor(orders, slice.Index(0)), o.encodeCursor(orders, slice.Index(n-1))
+		if o.before != "" {
+			page.Next = last
+			if more {
+				page.Prev = first
+			}
+		} else {
+			if more {
+				page.Next = last
+			}
+			if o.after != "" {
+				page.Prev = first
+			}
+		}
+	}
+
+	return page, nil
+}
+
+// keysetOrders returns orders of the query set with the primary key
+// as the tie breaker, only fields of the model can be used.
+func (o *querySet) keysetOrders() ([]string, error) {
+	fe := new(FieldsError)
+	orders := make([]string, 0, len(o.orders)+1)
+	hasPk := false
+	for _, order := range o.orders {
+		name := strings.TrimPrefix(order, "-")
+		fi, ok := o.mi.fields.GetByAny(name)
+		if !ok || strings.Contains(name, ExprSep) {
+			name = strings.Replace(name, ExprSep, ".", -1)
+			fe.add("orderby", name, "The %s field is not supported by cursor pagination.", name)
+			continue
+		}
+
+		// nullable value can not be compared with the next rows.
+		if fi.null {
+			name = strings.Replace(name, ExprSep, ".", -1)
+			fe.add("orderby", name, "The %s field is nullable, not supported by cursor pagination.", name)
+			continue
+		}
+
+		if fi == o.mi.fields.pk {
+			hasPk = true
+		}
+		orders = append(orders, order)
+	}
+
+	if len(fe.Errors) > 0 {
+		return nil, fe
+	}
+
+	if !hasPk {
+		orders = append(orders, o.mi.fields.pk.name)
+	}
+
+	return orders, nil
+}
+
+// encodeCursor returns signed cursor of the row.
+func (o *querySet) encodeCursor(orders []string, row reflect.Value) string {
+	ind := reflect.Indirect(row)
+	t := cursorToken{Orders: orders, Values: make([]interface{}, len(orders))}
+	for i, order := range orders {
+		fi, _ := o.mi.fields.GetByAny(strings.TrimPrefix(order, "-"))
+		v := ind.FieldByIndex(fi.fieldIndex)
+		if fi.rel {
+			if !v.IsNil() {
+				_, t.Values[i], _ = getExistPk(fi.relModelInfo, reflect.Indirect(v))
+			}
+		} else if v.Kind() != reflect.Ptr || !v.IsNil() {
+			// integer of named type e.g ID is marshaled by its own.
+			switch v = reflect.Indirect(v); {
+			case fi.fieldType&IsPositiveIntegerField > 0:
+				t.Values[i] = v.Uint()
+			case fi.fieldType&IsIntegerField > 0:
+				t.Values[i] = v.Int()
+			default:
+				t.Values[i] = v.Interface()
+			}
+		}
+	}
+
+	payload, _ := json.Marshal(t)
+	return base64.RawURLEncoding.EncodeToString(payload) + "." + base64.RawURLEncoding.EncodeToString(cursorSign(payload))
+}
+
+// decodeCursor verify the cursor and returns the values of the orders.
+func (o *querySet) decodeCursor(cursor string, orders []string) ([]interface{}, error) {
+	parts := strings.Split(cursor, ".")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("wrong cursor format")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(sig, cursorSign(payload)) {
+		return nil, fmt.Errorf("wrong cursor signature")
+	}
+
+	var t cursorToken
+	d := json.NewDecoder(bytes.NewReader(payload))
+	d.UseNumber()
+	if err = d.Decode(&t); err != nil {
+		return nil, err
+	}
+
+	if strings.Join(t.Orders, ",") != strings.Join(orders, ",") || len(t.Values) != len(orders) {
+		return nil, fmt.Errorf("cursor of different orders")
+	}
+
+	for i, order := range orders {
+		fi, _ := o.mi.fields.GetByAny(strings.TrimPrefix(order, "-"))
+		switch v := t.Values[i].(type) {
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				t.Values[i] = n
+			} else if f, err := v.Float64(); err == nil {
+				t.Values[i] = f
+			}
+		case string:
+			if fi.fieldType == TypeDateField || fi.fieldType == TypeDateTimeField || fi.fieldType == TypeTimeField {
+				if tm, err := time.Parse(time.RFC3339Nano, v); err == nil {
+					t.Values[i] = tm
+				}
+			}
+		}
+	}
+
+	return t.Values, nil
+}
+
+// keysetCond returns condition of the rows after the values on the orders,
+// e.g (a > ?) OR (a = ? AND b < ?) for orders a, -b.
+func keysetCond(orders []string, values []interface{}) *Condition {
+	c := NewCondition()
+	for i, order := range orders {
+		g := NewCondition()
+		for j := 0; j < i; j++ {
+			g = g.And(strings.TrimPrefix(orders[j], "-"), values[j])
+		}
+
+		if strings.HasPrefix(order, "-") {
+			g = g.And(order[1:]+ExprSep+"lt", values[i])
+		} else {
+			g = g.And(order+ExprSep+"gt", values[i])
+		}
+		c = c.OrCond(g)
+	}
+
+	return c
+}
+
+// reverseOrders returns the orders with opposite direction.
+func reverseOrders(orders []string) []string {
+	r := make([]string, len(orders))
+	for i, order := range orders {
+		if strings.HasPrefix(order, "-") {
+			r[i] = order[1:]
+		} else {
+			r[i] = "-" + order
+		}
+	}
+
+	return r
+}
+
+// cursorSign returns signature of the cursor payload.
+func cursorSign(payload []byte) []byte {
+	h := hmac.New(sha256.New, CursorSecret)
+	h.Write(payload)
+	return h.Sum(nil)
+}
+
+// containsString returns true when one of the values is in the list.
+func containsString(list []string, values ...string) bool {
+	for _, s := range list {
+		for _, v := range values {
+			if s == v {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// randomSecret returns random key of the cursor signature.
+func randomSecret() []byte {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return b
+}
@@ -0,0 +1,358 @@
+// Copyright 2016 PT. Qasico Teknologi Indonesia. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/alfatih/irhabi/common"
+)
+
+type (
+	// FilterExpr node of the parsed filter expression.
+	FilterExpr interface {
+		// Condition compile the node into orm condition.
+		Condition() *Condition
+	}
+
+	// FilterGroup expressions combined with the logical operator,
+	// Op is one of and, or and not.
+	FilterGroup struct {
+		Op    string
+		Exprs []FilterExpr
+		Pos   int
+	}
+
+	// FilterPredicate comparison of the field e.g status:eq:active,
+	// Field is the request notation e.g group.name.
+	FilterPredicate struct {
+		Field    string
+		Operator string
+		Values   []interface{}
+		Pos      int
+	}
+
+	// FilterError syntax error of the filter expression,
+	// Pos is the position of the character starting from 1.
+	FilterError struct {
+		Pos     int
+		Message string
+	}
+
+	// filterParser recursive descent parser of the filter expression.
+	filterParser struct {
+		s   string
+		pos int
+	}
+)
+
+// Error returns message of the error with the position.
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Pos)
+}
+
+// ParseFilter parse the filter expression into tree of FilterExpr,
+// the grammar of the RequestQuery `filter` query param:
+//
+//	filter    = list
+//	list      = term { "," term }
+//	term      = group | predicate
+//	group     = [ "and" | "or" | "not" ] "(" list ")"
+//	predicate = field ":" operator ":" value { "|" value }
+//	field     = letter, digit, "_" or "." e.g group.name
+//	operator  = exact | iexact | contains | icontains | gt | gte | lt | lte | eq | ne | nq |
+//	            startswith | endswith | istartswith | iendswith | in | between | isnull
+//	value     = '"' quoted string '"' | true | false | number | bare string
+//
+// Field with .e suffix e.g group.id.e is using the obfuscated id of common.EncodeID as the values.
+// Terms of the list are combined with AND, not group negates AND of the list.
+// Bare value is ended by unescaped "," "|" "(" or ")", backslash escapes the next
+// character and the value with escapes is always a string, quoted value is always a string.
+// e.g:
+//
+//	(status:eq:active,or(age:gt:30,vip:eq:true))
+//	name:icontains:"doe, jr",group.id:in:1|2|3,deleted_at:isnull:true
+func ParseFilter(s string) (FilterExpr, error) {
+	p := &filterParser{s: s}
+	exprs, err := p.list()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.space(); p.pos < len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos])
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+
+	return &FilterGroup{Op: "and", Exprs: exprs, Pos: 1}, nil
+}
+
+// Condition compile the group into orm condition.
+func (g *FilterGroup) Condition() *Condition {
+	c := NewCondition()
+	for i, e := range g.Exprs {
+		or := g.Op == "or" && i > 0
+		if p, ok := e.(*FilterPredicate); ok {
+			if or {
+				c = c.Or(p.expr(), p.Values...)
+			} else {
+				c = c.And(p.expr(), p.Values...)
+			}
+		} else if or {
+			c = c.OrCond(e.Condition())
+		} else {
+			c = c.AndCond(e.Condition())
+		}
+	}
+
+	if g.Op == "not" {
+		return NewCondition().AndNotCond(c)
+	}
+
+	return c
+}
+
+// Condition compile the predicate into orm condition.
+func (p *FilterPredicate) Condition() *Condition {
+	return NewCondition().And(p.expr(), p.Values...)
+}
+
+// expr returns orm expression of the predicate e.g group__name__icontains.
+func (p *FilterPredicate) expr() string {
+	return strings.Replace(p.Field, ".", ExprSep, -1) + ExprSep + p.Operator
+}
+
+// walkFilter call fn for each predicate of the expression.
+func walkFilter(e FilterExpr, fn func(p *FilterPredicate)) {
+	switch x := e.(type) {
+	case *FilterPredicate:
+		fn(x)
+	case *FilterGroup:
+		for _, c := range x.Exprs {
+			walkFilter(c, fn)
+		}
+	}
+}
+
+// list parse terms separated by comma.
+func (p *filterParser) list() (exprs []FilterExpr, err error) {
+	for {
+		var e FilterExpr
+		if e, err = p.term(); err != nil {
+			return nil, err
+		}
+
+		exprs = append(exprs, e)
+		if !p.consume(',') {
+			return exprs, nil
+		}
+	}
+}
+
+// term parse a group or a predicate.
+func (p *filterParser) term() (FilterExpr, error) {
+	p.space()
+	pos := p.pos + 1
+
+	op := "and"
+	if !p.consume('(') {
+		name := p.ident()
+		if name == "" {
+			return nil, p.errorf("expected field or group")
+		}
+
+		lower := strings.ToLower(name)
+		if !(lower == "and" || lower == "or" || lower == "not") || !p.consume('(') {
+			return p.predicate(name, pos)
+		}
+		op = lower
+	}
+
+	exprs, err := p.list()
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.consume(')') {
+		return nil, p.errorf("expected ')'")
+	}
+
+	if len(exprs) == 1 && op == "and" {
+		return exprs[0], nil
+	}
+
+	return &FilterGroup{Op: op, Exprs: exprs, Pos: pos}, nil
+}
+
+// predicate parse operator and values of the field.
+func (p *filterParser) predicate(field string, pos int) (FilterExpr, error) {
+	if !p.consume(':') {
+		return nil, p.errorf("expected ':' after field %s", field)
+	}
+
+	p.space()
+	opPos := p.pos
+	op := strings.ToLower(p.ident())
+	if !operators[op] {
+		p.pos = opPos
+		return nil, p.errorf("unknown operator %q", op)
+	}
+
+	if !p.consume(':') {
+		return nil, p.errorf("expected ':' after operator %s", op)
+	}
+
+	fp := &FilterPredicate{Field: strings.TrimSuffix(field, ".e"), Operator: op, Pos: pos}
+	obfuscated := fp.Field != field
+	for {
+		start := p.pos
+		v, err := p.value(obfuscated)
+		if err != nil {
+			return nil, err
+		}
+
+		if obfuscated {
+			if v, err = common.DecodeID(v.(string)); err != nil {
+				p.pos = start
+				return nil, p.errorf("invalid id of field %s", fp.Field)
+			}
+		}
+
+		fp.Values = append(fp.Values, v)
+		if !p.consume('|') {
+			break
+		}
+	}
+
+	switch {
+	case op == "between" && len(fp.Values) != 2:
+		return nil, &FilterError{Pos: pos, Message: "between operator needs 2 values"}
+	case op == "isnull":
+		if _, ok := fp.Values[0].(bool); !ok || len(fp.Values) != 1 {
+			return nil, &FilterError{Pos: pos, Message: "isnull operator needs true or false"}
+		}
+	case op != "in" && op != "between" && len(fp.Values) != 1:
+		return nil, &FilterError{Pos: pos, Message: fmt.Sprintf("%s operator needs 1 value", op)}
+	}
+
+	return fp, nil
+}
+
+// value parse quoted or bare value, raw value is not converted into typed value.
+func (p *filterParser) value(raw bool) (interface{}, error) {
+	start := p.pos
+	var b strings.Builder
+	if p.pos < len(p.s) && p.s[p.pos] == '"' {
+		for p.pos++; p.pos < len(p.s); p.pos++ {
+			switch c := p.s[p.pos]; c {
+			case '\\':
+				if p.pos++; p.pos >= len(p.s) {
+					return nil, p.errorf("unterminated string")
+				}
+				b.WriteByte(p.s[p.pos])
+			case '"':
+				p.pos++
+				return b.String(), nil
+			default:
+				b.WriteByte(c)
+			}
+		}
+
+		p.pos = start
+		return nil, p.errorf("unterminated string")
+	}
+
+	escaped := false
+	for ; p.pos < len(p.s); p.pos++ {
+		c := p.s[p.pos]
+		if c == ',' || c == '|' || c == '(' || c == ')' {
+			break
+		}
+
+		if c == '\\' {
+			if p.pos++; p.pos >= len(p.s) {
+				return nil, p.errorf("unexpected end after escape")
+			}
+			c, escaped = p.s[p.pos], true
+		}
+		b.WriteByte(c)
+	}
+
+	v := b.String()
+	if escaped {
+		return v, nil
+	}
+
+	if v == "" {
+		return nil, p.errorf("expected value")
+	}
+
+	if raw {
+		return v, nil
+	}
+
+	return filterValue(v), nil
+}
+
+// ident parse field, operator or group keyword.
+func (p *filterParser) ident() string {
+	start := p.pos
+	for ; p.pos < len(p.s); p.pos++ {
+		c := p.s[p.pos]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.') {
+			break
+		}
+	}
+
+	return p.s[start:p.pos]
+}
+
+// consume skip spaces and the character when it is the next.
+func (p *filterParser) consume(c byte) bool {
+	p.space()
+	if p.pos < len(p.s) && p.s[p.pos] == c {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+// space skip the white spaces.
+func (p *filterParser) space() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// errorf returns error at the current position.
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return &FilterError{Pos: p.pos + 1, Message: fmt.Sprintf(format, args...)}
+}
+
+// filterValue returns typed value of the bare value.
+func filterValue(v string) interface{} {
+	switch v {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return i
+	}
+
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f
+	}
+
+	return v
+}
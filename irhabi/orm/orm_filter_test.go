@@ -0,0 +1,133 @@
+package orm
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alfatih/irhabi/common"
+)
+
+func TestParseFilter(t *testing.T) {
+	e, err := ParseFilter(`(status:eq:active,or(age:gt:30,vip:eq:true))`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g, ok := e.(*FilterGroup)
+	if !ok || g.Op != "and" || len(g.Exprs) != 2 {
+		t.Fatalf("wrong root %#v", e)
+	}
+
+	if p := g.Exprs[0].(*FilterPredicate); p.Field != "status" || p.Operator != "eq" || !reflect.DeepEqual(p.Values, []interface{}{"active"}) {
+		t.Errorf("wrong predicate %#v", p)
+	}
+
+	or, ok := g.Exprs[1].(*FilterGroup)
+	if !ok || or.Op != "or" || len(or.Exprs) != 2 {
+		t.Fatalf("wrong or group %#v", g.Exprs[1])
+	}
+
+	if p := or.Exprs[0].(*FilterPredicate); !reflect.DeepEqual(p.Values, []interface{}{int64(30)}) {
+		t.Errorf("wrong typed value %#v", p.Values)
+	}
+
+	if p := or.Exprs[1].(*FilterPredicate); !reflect.DeepEqual(p.Values, []interface{}{true}) {
+		t.Errorf("wrong typed value %#v", p.Values)
+	}
+
+	c := e.Condition()
+	if len(c.params) != 2 || !c.params[1].isCond || c.params[1].isOr {
+		t.Fatalf("wrong condition %#v", c.params)
+	}
+
+	if oc := c.params[1].cond; len(oc.params) != 2 || oc.params[0].isOr || !oc.params[1].isOr {
+		t.Errorf("wrong or condition %#v", oc.params)
+	}
+}
+
+func TestParseFilterValues(t *testing.T) {
+	var cases = []struct {
+		filter string
+		expr   string
+		values []interface{}
+	}{
+		{`name:icontains:"doe, jr"`, "name__icontains", []interface{}{"doe, jr"}},
+		{`name:exact:a\,b\|c`, "name__exact", []interface{}{"a,b|c"}},
+		{`code:exact:\123`, "code__exact", []interface{}{"123"}},
+		{`group.id:in:1|2|3`, "group__id__in", []interface{}{int64(1), int64(2), int64(3)}},
+		{`price:between:1.5|10`, "price__between", []interface{}{1.5, int64(10)}},
+		{`deleted_at:isnull:true`, "deleted_at__isnull", []interface{}{true}},
+		{` name : startswith:"say \"hi\""`, "name__startswith", []interface{}{`say "hi"`}},
+	}
+
+	for _, c := range cases {
+		e, err := ParseFilter(c.filter)
+		if err != nil {
+			t.Errorf("%s: %s", c.filter, err)
+			continue
+		}
+
+		p := e.(*FilterPredicate)
+		if p.expr() != c.expr || !reflect.DeepEqual(p.Values, c.values) {
+			t.Errorf("%s: got %s %#v", c.filter, p.expr(), p.Values)
+		}
+	}
+}
+
+func TestParseFilterNot(t *testing.T) {
+	e, err := ParseFilter(`not(status:eq:deleted,archived:eq:true)`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := e.Condition()
+	if len(c.params) != 1 || !c.params[0].isNot || len(c.params[0].cond.params) != 2 {
+		t.Errorf("wrong not condition %#v", c.params)
+	}
+}
+
+func TestParseFilterError(t *testing.T) {
+	var cases = []struct {
+		filter string
+		pos    int
+		err    string
+	}{
+		{`status:eq:active,`, 18, "expected field or group at position 18"},
+		{`status:like:active`, 8, `unknown operator "like" at position 8`},
+		{`(status:eq:active`, 18, "expected ')' at position 18"},
+		{`status:eq:active)`, 17, `unexpected ')' at position 17`},
+		{`name:eq:"doe`, 9, "unterminated string at position 9"},
+		{`status`, 7, "expected ':' after field status at position 7"},
+		{`price:between:1`, 1, "between operator needs 2 values at position 1"},
+		{`a:eq:1,deleted_at:isnull:yes`, 8, "isnull operator needs true or false at position 8"},
+		{`status:eq:`, 11, "expected value at position 11"},
+	}
+
+	for _, c := range cases {
+		_, err := ParseFilter(c.filter)
+		fe, ok := err.(*FilterError)
+		if !ok {
+			t.Errorf("%s: expected filter error, got %v", c.filter, err)
+			continue
+		}
+
+		if fe.Pos != c.pos || fe.Error() != c.err {
+			t.Errorf("%s: got %d %s", c.filter, fe.Pos, fe.Error())
+		}
+	}
+}
+
+func TestParseFilterID(t *testing.T) {
+	e, err := ParseFilter(`group.id.e:in:` + common.EncodeID(1) + `|` + common.EncodeID(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p := e.(*FilterPredicate); p.Field != "group.id" || !reflect.DeepEqual(p.Values, []interface{}{int64(1), int64(20)}) {
+		t.Errorf("wrong predicate %#v", p)
+	}
+
+	if _, err = ParseFilter(`id.e:eq:12`); err == nil || err.Error() != "invalid id of field id at position 9" {
+		t.Errorf("wrong error %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright 2016 PT. Qasico Teknologi Indonesia. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"github.com/alfatih/irhabi/common"
+)
+
+// ID integer field that stored as bigint on database and obfuscated
+// with common.EncodeID on json, xml, form and path binding, zero ID is an empty string.
+// for example:
+//
+//	type User struct {
+//		ID    orm.ID `orm:"column(id);auto" json:"id"`
+//		Group *Group `orm:"column(group_id);rel(fk)" json:"group"`
+//	}
+type ID int64
+
+// ParseID returns ID of the obfuscated value,
+// value that modified returns *common.DecryptionError.
+func ParseID(v string) (ID, error) {
+	if v == "" {
+		return 0, nil
+	}
+
+	id, err := common.DecodeID(v)
+	return ID(id), err
+}
+
+// String returns obfuscated value of the ID.
+func (id ID) String() string {
+	if id == 0 {
+		return ""
+	}
+
+	return common.EncodeID(int64(id))
+}
+
+// MarshalText implement encoding.TextMarshaler.
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implement encoding.TextUnmarshaler.
+func (id *ID) UnmarshalText(b []byte) (err error) {
+	*id, err = ParseID(string(b))
+	return
+}
@@ -0,0 +1,38 @@
+package orm
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/alfatih/irhabi/common"
+)
+
+func TestID(t *testing.T) {
+	var m struct {
+		ID    ID  `json:"id"`
+		Group *ID `json:"group"`
+		Empty ID  `json:"empty"`
+	}
+
+	g := ID(7)
+	m.ID, m.Group = 1<<40, &g
+	b, err := json.Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `{"id":"` + common.EncodeID(1<<40) + `","group":"` + common.EncodeID(7) + `","empty":""}`; string(b) != expected {
+		t.Errorf("wrong json %s, expected %s", b, expected)
+	}
+
+	m.ID, m.Group = 0, nil
+	if err = json.Unmarshal(b, &m); err != nil || m.ID != 1<<40 || m.Group == nil || *m.Group != 7 || m.Empty != 0 {
+		t.Errorf("wrong unmarshal %#v %v", m, err)
+	}
+
+	for _, v := range []string{`{"id":1}`, `{"id":"1"}`, `{"id":"` + common.EncodeID(1)[1:] + `0"}`} {
+		if err = json.Unmarshal([]byte(v), &m); err == nil {
+			t.Errorf("%s: expected error", v)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alfatih/irhabi/common/log"
@@ -19,13 +20,54 @@ func NewLog(out io.Writer) *logrus.Logger {
 	return l
 }
 
-func logQuery(alias *alias, operaton, query string, t time.Time, err error, args ...interface{}) {
+// QueryObserver function that called after every query executed by orm,
+// with name of the database alias, the operation e.g db.Query and the duration.
+type QueryObserver func(alias string, operation string, elapsed time.Duration, err error)
+
+var (
+	observerMu     sync.RWMutex
+	queryObservers []QueryObserver
+)
+
+// AddQueryObserver register function to observe the queries e.g collecting metrics,
+// queries are observed even the Debug is disabled.
+func AddQueryObserver(fn QueryObserver) {
+	observerMu.Lock()
+	defer observerMu.Unlock()
+
+	queryObservers = append(queryObservers, fn)
+}
+
+// observed returns true if the queries should be wrapped
+// by query logger, for debuging or observers.
+func observed() bool {
+	observerMu.RLock()
+	defer observerMu.RUnlock()
+
+	return Debug || len(queryObservers) > 0
+}
+
+func logQuery(alias *alias, fields logrus.Fields, operaton, query string, t time.Time, err error, args ...interface{}) {
+	elapsed := time.Now().Sub(t)
+
+	observerMu.RLock()
+	observers := queryObservers
+	observerMu.RUnlock()
+
+	for _, fn := range observers {
+		fn(alias.Name, operaton, elapsed, err)
+	}
+
+	if !Debug {
+		return
+	}
+
 	go func() {
-		sub := time.Now().Sub(t) / 1e5
+		sub := elapsed / 1e5
 		elsp := float64(int(sub)) / 10.0
 
 		query = fmt.Sprintf(strings.Replace(query, "?", "`%v`", -1), args...)
-		l := DebugLog.WithFields(logrus.Fields{
+		l := DebugLog.WithFields(fields).WithFields(logrus.Fields{
 			"latecy": fmt.Sprintf("%1.1fms", elsp),
 			"query":  query,
 		})
@@ -39,11 +81,12 @@ func logQuery(alias *alias, operaton, query string, t time.Time, err error, args
 }
 
 // statement query logger struct.
-// if dev mode, use stmtQueryLog, or use stmtQuerier.
+// if dev mode or observed, use stmtQueryLog, or use stmtQuerier.
 type stmtQueryLog struct {
-	alias *alias
-	query string
-	stmt  stmtQuerier
+	alias  *alias
+	fields logrus.Fields
+	query  string
+	stmt   stmtQuerier
 }
 
 var _ stmtQuerier = new(stmtQueryLog)
@@ -51,46 +94,48 @@ var _ stmtQuerier = new(stmtQueryLog)
 func (d *stmtQueryLog) Close() error {
 	a := time.Now()
 	err := d.stmt.Close()
-	logQuery(d.alias, "st.Close", d.query, a, err)
+	logQuery(d.alias, d.fields, "st.Close", d.query, a, err)
 	return err
 }
 
 func (d *stmtQueryLog) Exec(args ...interface{}) (sql.Result, error) {
 	a := time.Now()
 	res, err := d.stmt.Exec(args...)
-	logQuery(d.alias, "st.Exec", d.query, a, err, args...)
+	logQuery(d.alias, d.fields, "st.Exec", d.query, a, err, args...)
 	return res, err
 }
 
 func (d *stmtQueryLog) Query(args ...interface{}) (*sql.Rows, error) {
 	a := time.Now()
 	res, err := d.stmt.Query(args...)
-	logQuery(d.alias, "st.Query", d.query, a, err, args...)
+	logQuery(d.alias, d.fields, "st.Query", d.query, a, err, args...)
 	return res, err
 }
 
 func (d *stmtQueryLog) QueryRow(args ...interface{}) *sql.Row {
 	a := time.Now()
 	res := d.stmt.QueryRow(args...)
-	logQuery(d.alias, "st.QueryRow", d.query, a, nil, args...)
+	logQuery(d.alias, d.fields, "st.QueryRow", d.query, a, nil, args...)
 	return res
 }
 
-func newStmtQueryLog(alias *alias, stmt stmtQuerier, query string) stmtQuerier {
+func newStmtQueryLog(alias *alias, stmt stmtQuerier, query string, fields logrus.Fields) stmtQuerier {
 	d := new(stmtQueryLog)
 	d.stmt = stmt
 	d.alias = alias
+	d.fields = fields
 	d.query = query
 	return d
 }
 
 // database query logger struct.
-// if dev mode, use dbQueryLog, or use dbQuerier.
+// if dev mode or observed, use dbQueryLog, or use dbQuerier.
 type dbQueryLog struct {
-	alias *alias
-	db    dbQuerier
-	tx    txer
-	txe   txEnder
+	alias  *alias
+	fields logrus.Fields
+	db     dbQuerier
+	tx     txer
+	txe    txEnder
 }
 
 var _ dbQuerier = new(dbQueryLog)
@@ -100,49 +145,49 @@ var _ txEnder = new(dbQueryLog)
 func (d *dbQueryLog) Prepare(query string) (*sql.Stmt, error) {
 	a := time.Now()
 	stmt, err := d.db.Prepare(query)
-	logQuery(d.alias, "db.Prepare", query, a, err)
+	logQuery(d.alias, d.fields, "db.Prepare", query, a, err)
 	return stmt, err
 }
 
 func (d *dbQueryLog) Exec(query string, args ...interface{}) (sql.Result, error) {
 	a := time.Now()
 	res, err := d.db.Exec(query, args...)
-	logQuery(d.alias, "db.Exec", query, a, err, args...)
+	logQuery(d.alias, d.fields, "db.Exec", query, a, err, args...)
 	return res, err
 }
 
 func (d *dbQueryLog) Query(query string, args ...interface{}) (*sql.Rows, error) {
 	a := time.Now()
 	res, err := d.db.Query(query, args...)
-	logQuery(d.alias, "db.Query", query, a, err, args...)
+	logQuery(d.alias, d.fields, "db.Query", query, a, err, args...)
 	return res, err
 }
 
 func (d *dbQueryLog) QueryRow(query string, args ...interface{}) *sql.Row {
 	a := time.Now()
 	res := d.db.QueryRow(query, args...)
-	logQuery(d.alias, "db.QueryRow", query, a, nil, args...)
+	logQuery(d.alias, d.fields, "db.QueryRow", query, a, nil, args...)
 	return res
 }
 
 func (d *dbQueryLog) Begin() (*sql.Tx, error) {
 	a := time.Now()
 	tx, err := d.db.(txer).Begin()
-	logQuery(d.alias, "db.Begin", "START TRANSACTION", a, err)
+	logQuery(d.alias, d.fields, "db.Begin", "START TRANSACTION", a, err)
 	return tx, err
 }
 
 func (d *dbQueryLog) Commit() error {
 	a := time.Now()
 	err := d.db.(txEnder).Commit()
-	logQuery(d.alias, "tx.Commit", "COMMIT", a, err)
+	logQuery(d.alias, d.fields, "tx.Commit", "COMMIT", a, err)
 	return err
 }
 
 func (d *dbQueryLog) Rollback() error {
 	a := time.Now()
 	err := d.db.(txEnder).Rollback()
-	logQuery(d.alias, "tx.Rollback", "ROLLBACK", a, err)
+	logQuery(d.alias, d.fields, "tx.Rollback", "ROLLBACK", a, err)
 	return err
 }
 
@@ -150,9 +195,10 @@ func (d *dbQueryLog) SetDB(db dbQuerier) {
 	d.db = db
 }
 
-func newDbQueryLog(alias *alias, db dbQuerier) dbQuerier {
+func newDbQueryLog(alias *alias, db dbQuerier, fields logrus.Fields) dbQuerier {
 	d := new(dbQueryLog)
 	d.alias = alias
+	d.fields = fields
 	d.db = db
 	return d
 }
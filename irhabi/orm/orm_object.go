@@ -78,8 +78,8 @@ func newInsertSet(orm *orm, mi *modelInfo) (Inserter, error) {
 	if err != nil {
 		return nil, err
 	}
-	if Debug {
-		bi.stmt = newStmtQueryLog(orm.alias, st, query)
+	if observed() {
+		bi.stmt = newStmtQueryLog(orm.alias, st, query, orm.fields)
 	} else {
 		bi.stmt = st
 	}
@@ -16,6 +16,7 @@ package orm
 
 import (
 	"fmt"
+	"strings"
 )
 
 type colValue struct {
@@ -53,12 +54,24 @@ func ColValue(opt operator, value interface{}) interface{} {
 	return val
 }
 
+// search of the query set
+type querySearch struct {
+	term     string
+	fields   []string
+	fullText bool
+}
+
 // real query struct
 type querySet struct {
 	mi       *modelInfo
 	cond     *Condition
 	related  []string
 	relDepth int
+	fields   []string
+	search   *querySearch
+	after    string
+	before   string
+	err      error
 	limit    int64
 	offset   int64
 	groups   []string
@@ -147,6 +160,12 @@ func (o querySet) RelatedSel(params ...interface{}) QuerySeter {
 	return &o
 }
 
+// set fields to be selected when querying.
+func (o querySet) Select(fields ...string) QuerySeter {
+	o.fields = fields
+	return &o
+}
+
 // set condition to QuerySeter.
 func (o querySet) SetCond(cond *Condition) QuerySeter {
 	o.cond = cond
@@ -158,13 +177,39 @@ func (o querySet) GetCond() *Condition {
 	return o.cond
 }
 
+// search the term on the fields with OR of icontains condition.
+func (o querySet) Search(term string, fields ...string) QuerySeter {
+	o.search = &querySearch{term: term, fields: searchFields(fields)}
+	return &o
+}
+
+// search the term on the fields with full text search of the database,
+// fallback to Search when the database is not supporting it.
+func (o querySet) SearchFullText(term string, fields ...string) QuerySeter {
+	o.search = &querySearch{term: term, fields: searchFields(fields), fullText: true}
+	return &o
+}
+
+// set error that returned when the QuerySeter executed,
+// e.g the request query is violating the model rules.
+func (o querySet) setErr(err error) QuerySeter {
+	o.err = err
+	return &o
+}
+
 // return QuerySeter execution result number
 func (o *querySet) Count() (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
 	return o.orm.alias.DbBaser.Count(o.orm.db, o, o.mi, o.cond, o.orm.alias.TZ)
 }
 
 // check result empty or not after QuerySeter executed
 func (o *querySet) Exist() bool {
+	if o.err != nil {
+		return false
+	}
 	cnt, _ := o.orm.alias.DbBaser.Count(o.orm.db, o, o.mi, o.cond, o.orm.alias.TZ)
 	return cnt > 0
 }
@@ -191,12 +236,18 @@ func (o *querySet) PrepareInsert() (Inserter, error) {
 // query all data and map to containers.
 // cols means the columns when querying.
 func (o *querySet) All(container interface{}, cols ...string) (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
 	return o.orm.alias.DbBaser.ReadBatch(o.orm.db, o, o.mi, o.cond, container, o.orm.alias.TZ, cols)
 }
 
 // query one row data and map to containers.
 // cols means the columns when querying.
 func (o *querySet) One(container interface{}, cols ...string) error {
+	if o.err != nil {
+		return o.err
+	}
 	o.limit = 1
 	num, err := o.orm.alias.DbBaser.ReadBatch(o.orm.db, o, o.mi, o.cond, container, o.orm.alias.TZ, cols)
 	if err != nil {
@@ -216,18 +267,27 @@ func (o *querySet) One(container interface{}, cols ...string) error {
 // expres means condition expression.
 // it converts data to []map[column]value.
 func (o *querySet) Values(results *[]Params, exprs ...string) (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
 	return o.orm.alias.DbBaser.ReadValues(o.orm.db, o, o.mi, o.cond, exprs, results, o.orm.alias.TZ)
 }
 
 // query all data and map to [][]interface
 // it converts data to [][column_index]value
 func (o *querySet) ValuesList(results *[]ParamsList, exprs ...string) (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
 	return o.orm.alias.DbBaser.ReadValues(o.orm.db, o, o.mi, o.cond, exprs, results, o.orm.alias.TZ)
 }
 
 // query all data and map to []interface.
 // it's designed for one row record set, auto change to []value, not [][column]value.
 func (o *querySet) ValuesFlat(result *ParamsList, expr string) (int64, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
 	return o.orm.alias.DbBaser.ReadValues(o.orm.db, o, o.mi, o.cond, []string{expr}, result, o.orm.alias.TZ)
 }
 
@@ -266,3 +326,13 @@ func newQuerySet(orm *orm, mi *modelInfo) QuerySeter {
 	o.orm = orm
 	return o
 }
+
+// searchFields returns fields in orm expression, e.g group.name to group__name.
+func searchFields(fields []string) []string {
+	exprs := make([]string, len(fields))
+	for i, f := range fields {
+		exprs[i] = strings.Replace(f, ".", ExprSep, -1)
+	}
+
+	return exprs
+}
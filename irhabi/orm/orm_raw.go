@@ -37,8 +37,8 @@ func newRawPreparer(rs *rawSet) (RawPreparer, error) {
 	if err != nil {
 		return nil, err
 	}
-	if Debug {
-		o.stmt = newStmtQueryLog(rs.orm.alias, st, query)
+	if observed() {
+		o.stmt = newStmtQueryLog(rs.orm.alias, st, query, rs.orm.fields)
 	} else {
 		o.stmt = st
 	}
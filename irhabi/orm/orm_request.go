@@ -13,13 +13,22 @@ import (
 	"github.com/alfatih/irhabi/common"
 )
 
+// LegacyConditions read the legacy `conditions` query param on RequestQuery.ReadFromContext,
+// disable it to only accept the `filter` expression, see ParseFilter.
+var LegacyConditions = true
+
 type RequestQuery struct {
 	Conditions []map[string]string
+	Filter     FilterExpr
+	Search     string
 	Fields     []string
+	Cursor     string
+	Before     string
 	OrderBy    []string
 	Embeds     []string
 	Offset     int
 	Limit      int
+	err        error
 }
 
 // Query make new query setter based on request query.
@@ -43,7 +52,26 @@ func (rq *RequestQuery) ExcludeEmbeds(customEmbeds string) bool {
 }
 
 // Apply set data request query into query setter.
+// Query of the model that implementing QueryRuler is validated,
+// violations are returned when the query setter executed, and the search
+// is applied on the fields of the model that implementing QuerySearcher.
 func (rq *RequestQuery) Apply(qs QuerySeter) QuerySeter {
+	// apply rules and search of the model
+	if q, ok := qs.(*querySet); ok {
+		model := q.mi.addrField.Interface()
+		if err := rq.Validate(model); err != nil {
+			qs = q.setErr(err)
+		}
+
+		if s, ok := model.(QuerySearcher); ok && rq.Search != "" {
+			if search := s.QuerySearch(); search.FullText {
+				qs = qs.SearchFullText(rq.Search, search.Fields...)
+			} else {
+				qs = qs.Search(rq.Search, search.Fields...)
+			}
+		}
+	}
+
 	// apply conditions
 	qs = qs.SetCond(rq.GetCondition())
 
@@ -53,12 +81,24 @@ func (rq *RequestQuery) Apply(qs QuerySeter) QuerySeter {
 		qs = qs.RelatedSel(j...)
 	}
 
+	// apply fields
+	if len(rq.Fields) > 0 {
+		qs = qs.Select(rq.Fields...)
+	}
+
 	// apply order by
 	qs = qs.OrderBy(rq.OrderBy...)
 
 	// apply limit
 	qs = qs.Limit(rq.Limit, rq.Offset)
 
+	// apply cursor of keyset pagination
+	if rq.Cursor != "" {
+		qs = qs.After(rq.Cursor)
+	} else if rq.Before != "" {
+		qs = qs.Before(rq.Before)
+	}
+
 	return qs
 }
 
@@ -72,7 +112,8 @@ func (rq *RequestQuery) ReadFromContext(params url.Values) *RequestQuery {
 	}
 
 	if pf := params.Get("fields"); pf != "" {
-		rq.Fields = strings.Split(pf, ",")
+		k := strings.Replace(pf, ".", "__", -1)
+		rq.Fields = strings.Split(k, ",")
 	}
 
 	if po := params.Get("orderby"); po != "" {
@@ -85,7 +126,23 @@ func (rq *RequestQuery) ReadFromContext(params url.Values) *RequestQuery {
 		rq.Embeds = strings.Split(k, ",")
 	}
 
-	if pc := params.Get("conditions"); pc != "" {
+	if q := strings.TrimSpace(params.Get("q")); q != "" {
+		rq.Search = q
+	}
+
+	if pc := params.Get("cursor"); pc != "" {
+		rq.Cursor = pc
+	}
+
+	if pb := params.Get("before"); pb != "" {
+		rq.Before = pb
+	}
+
+	if pf := params.Get("filter"); pf != "" {
+		rq.Filter, rq.err = ParseFilter(pf)
+	}
+
+	if pc := params.Get("conditions"); pc != "" && LegacyConditions {
 		for _, cond := range strings.Split(pc, "|") {
 			var bc = make(map[string]string)
 			for _, partcond := range strings.Split(cond, "%2C") {
@@ -93,13 +150,14 @@ func (rq *RequestQuery) ReadFromContext(params url.Values) *RequestQuery {
 				if len(kv) > 2 {
 					bc[kv[0]] = fmt.Sprintf("%s:%s:%s", kv[1], kv[2], kv[3])
 				} else if len(kv) == 2 {
-					// need to check the value is should be decrypt or not
-					// if the decrypt is success, we need the value to be converted into
-					// real values.
-					lastStr := kv[0][len(kv[0])-2:]
-					if vd, err := common.Decrypt(kv[1]); err == nil && lastStr == ".e" {
-						k := strings.Replace(kv[0], ".e", "", -1)
-						bc[k] = strconv.Itoa(int(vd))
+					// field with .e suffix is using obfuscated id, the value
+					// is converted into real id and modified value is rejected.
+					if k := strings.TrimSuffix(kv[0], ".e"); k != kv[0] {
+						if vd, err := common.Decrypt(kv[1]); err == nil {
+							bc[k] = strconv.FormatInt(vd, 10)
+						} else if rq.err == nil {
+							rq.err = &FieldsError{Errors: map[string]string{"conditions." + k: fmt.Sprintf("The %s value is invalid.", k)}}
+						}
 					} else {
 						bc[kv[0]] = kv[1]
 					}
@@ -143,6 +201,10 @@ func (rq *RequestQuery) GetCondition() *Condition {
 		c = c.AndCond(cd)
 	}
 
+	if rq.Filter != nil {
+		c = c.AndCond(rq.Filter.Condition())
+	}
+
 	return c
 }
 
@@ -0,0 +1,169 @@
+// Copyright 2016 PT. Qasico Teknologi Indonesia. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"strings"
+)
+
+type (
+	// QueryRules fields of the model that can be used by RequestQuery,
+	// fields are written as the request notation e.g group.name.
+	QueryRules struct {
+		Filters    map[string][]string // Filterable fields with the allowed operators, empty operators allow all
+		Sorts      []string            // Sortable fields
+		Embeds     []string            // Embeddable relations, relation also allow the parent e.g group.company allow group
+		EmbedDepth int                 // Maximum depth of the embeds, zero means no limit
+	}
+
+	// QueryRuler model that restrict the fields can be filtered,
+	// sorted and embedded by RequestQuery, models that not implementing
+	// this interface are not restricted.
+	// for example:
+	//	func (m *User) QueryRules() orm.QueryRules {
+	//		return orm.QueryRules{
+	//			Filters: map[string][]string{"name": {"exact", "icontains"}, "group.id": nil},
+	//			Sorts:   []string{"id", "name"},
+	//			Embeds:  []string{"group"},
+	//		}
+	//	}
+	QueryRuler interface {
+		QueryRules() QueryRules
+	}
+
+	// QuerySearch searchable fields of the model for RequestQuery `q` param,
+	// related fields is using "__" e.g group__name.
+	QuerySearch struct {
+		Fields   []string // Searchable fields
+		FullText bool     // Use full text search of mysql and postgres, ordered by the relevance
+	}
+
+	// QuerySearcher model that declare the searchable fields,
+	// `q` param is ignored for models that not implementing this interface.
+	// for example:
+	//	func (m *User) QuerySearch() orm.QuerySearch {
+	//		return orm.QuerySearch{Fields: []string{"name", "email", "group__name"}}
+	//	}
+	QuerySearcher interface {
+		QuerySearch() QuerySearch
+	}
+)
+
+// Validate check the request query against rules of the model,
+// violations are returned as *FieldsError keyed by the parameter,
+// the filter that failed to parse is returned as *FilterError.
+func (rq *RequestQuery) Validate(model interface{}) error {
+	if rq.err != nil {
+		return rq.err
+	}
+
+	r, ok := model.(QueryRuler)
+	if !ok {
+		return nil
+	}
+
+	rules := r.QueryRules()
+	fe := new(FieldsError)
+	filter := func(param string, field string, op string) {
+		if ops, ok := rules.Filters[field]; !ok {
+			fe.add(param, field, "The %s field is not filterable.", field)
+		} else if len(ops) > 0 && !inStrings(op, ops) {
+			fe.add(param, field+"."+op, "The %s field is not filterable with %s operator.", field, op)
+		}
+	}
+
+	for _, cond := range rq.Conditions {
+		for k := range cond {
+			field, op := conditionField(k)
+			filter("conditions", field, op)
+		}
+	}
+
+	if rq.Filter != nil {
+		walkFilter(rq.Filter, func(p *FilterPredicate) {
+			filter("filter", p.Field, p.Operator)
+		})
+	}
+
+	for _, o := range rq.OrderBy {
+		field := strings.Replace(strings.TrimPrefix(o, "-"), ExprSep, ".", -1)
+		if !inStrings(field, rules.Sorts) {
+			fe.add("orderby", field, "The %s field is not sortable.", field)
+		}
+	}
+
+	for _, e := range rq.Embeds {
+		field := strings.Replace(e, ExprSep, ".", -1)
+		if rules.EmbedDepth > 0 && strings.Count(field, ".") >= rules.EmbedDepth {
+			fe.add("embeds", field, "The %s embeds is deeper than %d.", field, rules.EmbedDepth)
+		} else if !embeddable(field, rules.Embeds) {
+			fe.add("embeds", field, "The %s field is not embeddable.", field)
+		}
+	}
+
+	// relation of the dotted fields is queried together,
+	// so it is restricted as the embeds.
+	for _, f := range rq.Fields {
+		field := strings.Replace(f, ExprSep, ".", -1)
+		i := strings.LastIndex(field, ".")
+		if i < 0 {
+			continue
+		}
+
+		if rel := field[:i]; rules.EmbedDepth > 0 && strings.Count(rel, ".") >= rules.EmbedDepth {
+			fe.add("fields", field, "The %s field is deeper than %d embeds.", field, rules.EmbedDepth)
+		} else if !embeddable(rel, rules.Embeds) {
+			fe.add("fields", field, "The %s field is not embeddable.", field)
+		}
+	}
+
+	if len(fe.Errors) > 0 {
+		return fe
+	}
+
+	return nil
+}
+
+// conditionField returns field and operator of the condition key,
+// e.g Or.group.name.icontains returns group.name and icontains.
+func conditionField(k string) (field string, op string) {
+	for _, p := range []string{"AndNot.", "OrNot.", "Or.", "And."} {
+		if strings.HasPrefix(k, p) {
+			k = strings.TrimPrefix(k, p)
+			break
+		}
+	}
+
+	k = strings.Replace(k, ExprSep, ".", -1)
+	if i := strings.LastIndex(k, "."); i > 0 {
+		if op = k[i+1:]; operators[op] || op == "null" || op == "notnull" {
+			return k[:i], op
+		}
+	}
+
+	return k, "exact"
+}
+
+// embeddable returns true when the relation or the child of relation is allowed.
+func embeddable(field string, embeds []string) bool {
+	for _, e := range embeds {
+		if e == field || strings.HasPrefix(e, field+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inStrings returns true when the value exists in the list.
+func inStrings(v string, list []string) bool {
+	for _, l := range list {
+		if l == v {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,135 @@
+// Copyright 2016 PT. Qasico Teknologi Indonesia. All rights reserved.
+// Use of this source code is governed by a MIT style
+// license that can be found in the LICENSE file.
+
+package orm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FieldsError error when the query is using fields that not exists
+// or not exposed by the model, errors are keyed by the parameter and the field
+// e.g fields.password.
+type FieldsError struct {
+	Errors map[string]string
+}
+
+// Error returns all the messages of the errors.
+func (e *FieldsError) Error() string {
+	var m []string
+	for _, v := range e.Errors {
+		m = append(m, v)
+	}
+	sort.Strings(m)
+
+	return strings.Join(m, " ")
+}
+
+// add the failure message of the field.
+func (e *FieldsError) add(param string, field string, format string, args ...interface{}) {
+	if e.Errors == nil {
+		e.Errors = make(map[string]string)
+	}
+
+	e.Errors[param+"."+field] = fmt.Sprintf(format, args...)
+}
+
+// jsonName returns name of the field on json serialization,
+// empty string means the field is not exposed.
+func (fi *fieldInfo) jsonName() string {
+	tag := fi.sf.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+
+	if n := strings.Split(tag, ",")[0]; n != "" {
+		return n
+	}
+
+	return fi.name
+}
+
+// GetByJSON returns field info by the json name, fallback to GetByAny,
+// fields that not exposed on json serialization are not returned.
+func (f *fields) GetByJSON(name string) (*fieldInfo, bool) {
+	for _, fi := range f.fields {
+		if fi.jsonName() == name {
+			return fi, true
+		}
+	}
+
+	if fi, ok := f.GetByAny(name); ok && fi.jsonName() != "" {
+		return fi, true
+	}
+
+	return nil, false
+}
+
+// selectFields resolve the fields of QuerySeter.Select into columns of the model
+// and columns of the related models keyed by the relation path.
+func selectFields(mi *modelInfo, fields []string) (cols []string, rels map[string][]string, err error) {
+	fe := new(FieldsError)
+	rels = make(map[string][]string)
+	seen := make(map[string]bool)
+	add := func(path string, column string) {
+		if seen[path+ExprSep+column] {
+			return
+		}
+		seen[path+ExprSep+column] = true
+
+		if path == "" {
+			cols = append(cols, column)
+		} else {
+			rels[path] = append(rels[path], column)
+		}
+	}
+
+	add("", mi.fields.pk.column)
+	for _, field := range fields {
+		var names []string
+		mmi := mi
+		exs := strings.Split(field, ExprSep)
+		name := strings.Join(exs, ".")
+		for i, ex := range exs {
+			fi, ok := mmi.fields.GetByJSON(ex)
+			if !ok {
+				if _, exists := mmi.fields.GetByAny(ex); exists {
+					fe.add("fields", name, "The %s field is not exposed.", name)
+				} else {
+					fe.add("fields", name, "The %s field is not exists.", name)
+				}
+				break
+			}
+
+			// reverse and many to many relation has no column to select.
+			if !fi.dbcol {
+				if i < len(exs)-1 {
+					fe.add("fields", name, "The %s field is not exists.", name)
+				}
+				break
+			}
+
+			path := strings.Join(names, ExprSep)
+			add(path, fi.column)
+			if i < len(exs)-1 {
+				if !fi.rel || fi.relModelInfo == nil {
+					fe.add("fields", name, "The %s field is not exists.", name)
+					break
+				}
+
+				names = append(names, fi.name)
+				mmi = fi.relModelInfo
+				add(strings.Join(names, ExprSep), mmi.fields.pk.column)
+			}
+		}
+	}
+
+	if len(fe.Errors) > 0 {
+		return nil, nil, fe
+	}
+
+	return cols, rels, nil
+}
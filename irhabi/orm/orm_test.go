@@ -16,7 +16,9 @@ package orm
 
 import (
 	"bytes"
+	"context"
 	"database/sql"
+	"encoding/base64"
 	"fmt"
 	"io/ioutil"
 	"math"
@@ -27,6 +29,8 @@ import (
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/alfatih/irhabi/common/log"
 )
 
 var _ = os.PathSeparator
@@ -194,6 +198,7 @@ func TestSyncDb(t *testing.T) {
 	RegisterModel(new(IntegerPk))
 	RegisterModel(new(UintPk))
 	RegisterModel(new(PtrPk))
+	RegisterModel(new(MemberGroup), new(Member))
 
 	err := RunSyncdb("default", true, Debug)
 	throwFail(t, err)
@@ -218,6 +223,7 @@ func TestRegisterModels(t *testing.T) {
 	RegisterModel(new(IntegerPk))
 	RegisterModel(new(UintPk))
 	RegisterModel(new(PtrPk))
+	RegisterModel(new(MemberGroup), new(Member))
 
 	BootStrap()
 
@@ -2216,6 +2222,272 @@ func TestPtrPk(t *testing.T) {
 	throwFail(t, AssertIs(num, 1))
 }
 
+func TestNewOrmWithContext(t *testing.T) {
+	defer func(d bool) { Debug = d }(Debug)
+	Debug = true
+
+	ctx := log.NewContext(context.Background(), log.Log.WithField("request_id", "abc"))
+	o := NewOrmWithContext(ctx).(*orm)
+	throwFail(t, AssertIs(o.fields["request_id"], "abc"))
+
+	// fields are kept when the alias is switched.
+	throwFailNow(t, o.Using("default"))
+	ql, ok := o.db.(*dbQueryLog)
+	throwFailNow(t, AssertIs(ok, true))
+	throwFail(t, AssertIs(ql.fields["request_id"], "abc"))
+
+	o = NewOrm().(*orm)
+	throwFail(t, AssertIs(len(o.fields), 0))
+}
+
+func TestInsertMemberData(t *testing.T) {
+	admins := &MemberGroup{Name: "admins"}
+	users := &MemberGroup{Name: "users"}
+	_, err := dORM.InsertMulti(2, []*MemberGroup{admins, users})
+	throwFailNow(t, err)
+	throwFailNow(t, dORM.Read(admins, "Name"))
+	throwFailNow(t, dORM.Read(users, "Name"))
+
+	score := func(n int) *int { return &n }
+	members := []*Member{
+		{Name: "jon 1", Age: 30, Score: score(80), Secret: "a", Group: admins},
+		{Name: "jon 2", Age: 25, Score: score(70), Secret: "b", Group: users},
+		{Name: "jon 3", Age: 41, Secret: "c", Group: users},
+		{Name: "arya", Age: 18, Score: score(90), Secret: "d", Group: users},
+		{Name: "sansa", Age: 22, Secret: "e", Group: admins},
+		{Name: "bran", Age: 15, Secret: "f"},
+		{Name: "rickon", Age: 12, Secret: "g"},
+	}
+	num, err := dORM.InsertMulti(len(members), members)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 7))
+}
+
+func TestSelect(t *testing.T) {
+	var m Member
+	err := dORM.QueryTable("member").Filter("name", "jon 3").Select("name", "group__name").One(&m)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(m.Name, "jon 3"))
+	throwFail(t, AssertIs(m.Age, 0))
+	throwFail(t, AssertIs(m.Secret, ""))
+	throwFailNow(t, AssertNot(m.Group, nil))
+	throwFail(t, AssertIs(m.Group.Name, "users"))
+	throwFail(t, AssertNot(m.Group.ID, 0))
+
+	var members []*Member
+	num, err := dORM.QueryTable("member").Select("age").OrderBy("-age").Limit(2).All(&members)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+	throwFail(t, AssertIs(members[0].Age, 41))
+	throwFail(t, AssertIs(members[0].Name, ""))
+	throwFail(t, AssertNot(members[0].ID, 0))
+	throwFail(t, AssertIs(members[1].Age, 30))
+
+	_, err = dORM.QueryTable("member").Select("name", "secret", "group__unknown").All(&members)
+	fe, ok := err.(*FieldsError)
+	throwFailNow(t, AssertIs(ok, true))
+	throwFail(t, AssertIs(len(fe.Errors), 2))
+	throwFail(t, AssertIs(fe.Errors["fields.secret"], "The secret field is not exposed."))
+	throwFail(t, AssertIs(fe.Errors["fields.group.unknown"], "The group.unknown field is not exists."))
+}
+
+func TestSearch(t *testing.T) {
+	qs := dORM.QueryTable("member")
+
+	num, err := qs.Search("JON", "name").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 3))
+
+	var members []*Member
+	num, err = qs.Search("admins", "name", "group.name").OrderBy("name").All(&members)
+	throwFailNow(t, err)
+	throwFailNow(t, AssertIs(num, 2))
+	throwFail(t, AssertIs(members[0].Name, "jon 1"))
+	throwFail(t, AssertIs(members[1].Name, "sansa"))
+
+	// sqlite is not supporting full text search, fallback to icontains.
+	num, err = qs.SearchFullText("an", "name", "group__name").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	num, err = qs.Filter("age__gte", 20).Search("users", "group__name").Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+}
+
+func TestSearchBatch(t *testing.T) {
+	o := NewOrm()
+	throwFailNow(t, o.Begin())
+	defer o.Rollback()
+
+	qs := o.QueryTable("member")
+	num, err := qs.Filter("age__gte", 0).Search("jon 3", "name").Update(Params{"age": 42})
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Filter("age", 42).Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Filter("age__gte", 0).Search("jon 3", "name").Delete()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 1))
+
+	num, err = qs.Search("users", "group__name").Delete()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 2))
+
+	num, err = qs.Count()
+	throwFail(t, err)
+	throwFail(t, AssertIs(num, 4))
+}
+
+func TestSearchFullTextSQL(t *testing.T) {
+	mi, ok := modelCache.get("member")
+	throwFailNow(t, AssertIs(ok, true))
+
+	s := &querySearch{term: "jon", fields: searchFields([]string{"name", "group.name"}), fullText: true}
+	var cases = []struct {
+		base    dbBaser
+		where   string
+		orderBy string
+	}{
+		{
+			newdbBaseMysql(),
+			"WHERE ( (MATCH (T0.`name`) AGAINST (? IN NATURAL LANGUAGE MODE) + MATCH (T1.`name`) AGAINST (? IN NATURAL LANGUAGE MODE)) > 0) ",
+			"ORDER BY (MATCH (T0.`name`) AGAINST (? IN NATURAL LANGUAGE MODE) + MATCH (T1.`name`) AGAINST (? IN NATURAL LANGUAGE MODE)) DESC, T0.`age` DESC ",
+		},
+		{
+			newdbBasePostgres(),
+			`WHERE ( to_tsvector(coalesce(T0."name"::text, '') || ' ' || coalesce(T1."name"::text, '')) @@ plainto_tsquery(?)) `,
+			`ORDER BY ts_rank(to_tsvector(coalesce(T0."name"::text, '') || ' ' || coalesce(T1."name"::text, '')), plainto_tsquery(?)) DESC, T0."age" DESC `,
+		},
+	}
+
+	for _, c := range cases {
+		tables := newDbTables(mi, c.base)
+		orderBy := tables.getOrderSQL([]string{"-age"})
+		var where string
+		var args []interface{}
+		tables.addSearchSQL(s, &where, &orderBy, &args, time.UTC)
+
+		throwFail(t, AssertIs(where, c.where))
+		throwFail(t, AssertIs(orderBy, c.orderBy))
+		throwFail(t, AssertIs(len(args), strings.Count(c.where+c.orderBy, "?")))
+		throwFail(t, AssertIs(strings.Contains(tables.getJoinSQL(), "member_group"), true))
+	}
+
+	// the columns of each table are matched together on mysql.
+	match, rank := newdbBaseMysql().FullTextSQL([][]string{{"T0.`name`", "T0.`bio`"}})
+	throwFail(t, AssertIs(match, "(MATCH (T0.`name`, T0.`bio`) AGAINST (? IN NATURAL LANGUAGE MODE)) > 0"))
+	throwFail(t, AssertIs(rank, "(MATCH (T0.`name`, T0.`bio`) AGAINST (? IN NATURAL LANGUAGE MODE))"))
+
+	match, rank = newdbBaseSqlite().FullTextSQL([][]string{{"T0.`name`"}})
+	throwFail(t, AssertIs(match, ""))
+	throwFail(t, AssertIs(rank, ""))
+}
+
+func TestCursor(t *testing.T) {
+	names := func(members []*Member) string {
+		var l []string
+		for _, m := range members {
+			l = append(l, m.Name)
+		}
+		return strings.Join(l, ",")
+	}
+
+	qs := dORM.QueryTable("member").OrderBy("-age").Limit(3)
+
+	var members []*Member
+	page, err := qs.AllCursor(&members)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(names(members), "jon 3,jon 1,jon 2"))
+	throwFail(t, AssertIs(page.Prev, ""))
+	throwFailNow(t, AssertNot(page.Next, ""))
+
+	page, err = qs.After(page.Next).AllCursor(&members)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(names(members), "sansa,arya,bran"))
+	throwFail(t, AssertNot(page.Prev, ""))
+	throwFailNow(t, AssertNot(page.Next, ""))
+
+	page, err = qs.After(page.Next).AllCursor(&members)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(names(members), "rickon"))
+	throwFail(t, AssertIs(page.Next, ""))
+	throwFailNow(t, AssertNot(page.Prev, ""))
+
+	page, err = qs.Before(page.Prev).AllCursor(&members)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(names(members), "sansa,arya,bran"))
+	throwFailNow(t, AssertNot(page.Prev, ""))
+
+	page, err = qs.Before(page.Prev).AllCursor(&members)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(names(members), "jon 3,jon 1,jon 2"))
+	throwFail(t, AssertIs(page.Prev, ""))
+
+	// the orders are selected together with the fields, so the cursor has the values.
+	sqs := qs.Select("name")
+	page, err = sqs.AllCursor(&members)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(names(members), "jon 3,jon 1,jon 2"))
+	page, err = sqs.After(page.Next).AllCursor(&members)
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(names(members), "sansa,arya,bran"))
+
+	page, err = qs.AllCursor(&members, "name")
+	throwFailNow(t, err)
+	page, err = qs.After(page.Next).AllCursor(&members, "name")
+	throwFailNow(t, err)
+	throwFail(t, AssertIs(names(members), "sansa,arya,bran"))
+
+	invalid := func(err error, param string) {
+		fe, ok := err.(*FieldsError)
+		throwFailNow(t, AssertIs(ok, true))
+		throwFail(t, AssertIs(fe.Errors[param], "The cursor is invalid."))
+	}
+
+	// modified payload is not matching the signature.
+	p := strings.Split(page.Next, ".")
+	payload, _ := base64.RawURLEncoding.DecodeString(p[0])
+	payload = bytes.Replace(payload, []byte("15"), []byte("99"), 1)
+	_, err = qs.After(base64.RawURLEncoding.EncodeToString(payload) + "." + p[1]).AllCursor(&members)
+	invalid(err, "cursor")
+
+	_, err = qs.Before("not-a-cursor").AllCursor(&members)
+	invalid(err, "before")
+
+	// cursor of the other orders.
+	_, err = qs.OrderBy("name").After(page.Next).AllCursor(&members)
+	invalid(err, "cursor")
+
+	_, err = qs.OrderBy("score").AllCursor(&members)
+	fe, ok := err.(*FieldsError)
+	throwFailNow(t, AssertIs(ok, true))
+	throwFail(t, AssertIs(fe.Errors["orderby.score"], "The score field is nullable, not supported by cursor pagination."))
+
+	_, err = qs.SearchFullText("jon", "name").AllCursor(&members)
+	fe, ok = err.(*FieldsError)
+	throwFailNow(t, AssertIs(ok, true))
+	throwFail(t, AssertIs(fe.Errors["q"], "Full text search is not supported by cursor pagination."))
+}
+
+func TestUnregisterDataBase(t *testing.T) {
+	throwFailNow(t, RegisterDataBase("unregister", DBARGS.Driver, DBARGS.Source))
+	throwFail(t, AssertIs(len(DataBaseAliases()), 2))
+
+	throwFailNow(t, UnregisterDataBase("unregister"))
+	throwFail(t, AssertIs(strings.Join(DataBaseAliases(), ","), "default"))
+	_, err := GetDB("unregister")
+	throwFail(t, AssertNot(err, nil))
+	throwFail(t, AssertNot(UnregisterDataBase("unregister"), nil))
+
+	// the alias name can be registered again.
+	throwFailNow(t, RegisterDataBase("unregister", DBARGS.Driver, DBARGS.Source))
+	throwFail(t, UnregisterDataBase("unregister"))
+}
+
 func TestSnake(t *testing.T) {
 	cases := map[string]string{
 		"i":           "i",
@@ -170,6 +170,34 @@ type QuerySeter interface {
 	//	qs.RelatedSel("profile").One(&user)
 	//	user.Profile.Age = 32
 	RelatedSel(params ...interface{}) QuerySeter
+	// set fields to be selected when All or One called without cols,
+	// fields are the json name, field name or column, relation fields can be
+	// selected with "__" and the relation will query together.
+	// fields that not exists or not exposed on json will return *FieldsError.
+	// for example:
+	//	qs.Select("id", "name", "profile__age").All(&users)
+	Select(fields ...string) QuerySeter
+	// search the term on the fields with OR of icontains condition,
+	// related fields is using "__" or ".".
+	// for example:
+	//	qs.Search("jon", "name", "email", "group__name").All(&users)
+	Search(term string, fields ...string) QuerySeter
+	// search the term on the fields with full text search of mysql
+	// (MATCH ... AGAINST) or postgres (to_tsvector), ordered by the relevance.
+	// mysql need FULLTEXT index of the fields of each table.
+	// other databases are using Search.
+	// for example:
+	//	qs.SearchFullText("jon snow", "name", "bio").All(&users)
+	SearchFullText(term string, fields ...string) QuerySeter
+	// set cursor of keyset pagination to query the rows after the row of the cursor,
+	// the cursor is from CursorPage of AllCursor.
+	// for example:
+	//	page, err := qs.OrderBy("-created_at").Limit(20).After(cursor).AllCursor(&posts)
+	After(cursor string) QuerySeter
+	// set cursor of keyset pagination to query the rows before the row of the cursor.
+	// for example:
+	//	page, err := qs.OrderBy("-created_at").Limit(20).Before(page.Prev).AllCursor(&posts)
+	Before(cursor string) QuerySeter
 	// Set Distinct
 	// for example:
 	//  o.QueryTable("policy").Filter("Groups__Group__Users__User", user).
@@ -211,6 +239,13 @@ type QuerySeter interface {
 	//	var users []*User
 	//	qs.All(&users) // users[0],users[1],users[2] ...
 	All(container interface{}, cols ...string) (int64, error)
+	// query one page of keyset pagination into the slice container, ordered by
+	// the OrderBy fields of the model and the primary key, returns signed cursor
+	// of the next and previous page, invalid cursor returns *FieldsError.
+	// for example:
+	//	page, err := qs.OrderBy("-created_at").Limit(20).After(cursor).AllCursor(&posts)
+	//	next, prev := page.Next, page.Prev
+	AllCursor(container interface{}, cols ...string) (*CursorPage, error)
 	// query one row data and map to containers.
 	// cols means the columns when querying.
 	// for example:
@@ -403,6 +438,7 @@ type dbBaser interface {
 	Delete(dbQuerier, *modelInfo, reflect.Value, *time.Location, []string) (int64, error)
 	ReadBatch(dbQuerier, *querySet, *modelInfo, *Condition, interface{}, *time.Location, []string) (int64, error)
 	SupportUpdateJoin() bool
+	FullTextSQL([][]string) (string, string)
 	UpdateBatch(dbQuerier, *querySet, *modelInfo, *Condition, Params, *time.Location) (int64, error)
 	DeleteBatch(dbQuerier, *querySet, *modelInfo, *Condition, *time.Location) (int64, error)
 	Count(dbQuerier, *querySet, *modelInfo, *Condition, *time.Location) (int64, error)
@@ -0,0 +1,178 @@
+package validation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Catalog is messages of the failure codes on a locale, params of the failure
+// are replaced on the message e.g "The :field must be greater than :min".
+type Catalog map[string]string
+
+// ValidatorParams parse parameter of the tag into params of the failure,
+// parameter of the tag that not registered is exposed as value.
+var ValidatorParams = map[string]func(param string) map[string]interface{}{
+	"lte":    maxParam,
+	"lt":     maxParam,
+	"gte":    minParam,
+	"gt":     minParam,
+	"range":  rangeParam,
+	"match":  patternParam,
+	"in":     valuesParam,
+	"not_in": valuesParam,
+}
+
+var catalogs = struct {
+	sync.RWMutex
+	locales map[string]Catalog
+}{locales: map[string]Catalog{
+	"en": {
+		"required":        "The :field field is required.",
+		"numeric":         "The :field must be a number.",
+		"alpha":           "The :field may only contain letters.",
+		"alpha_num":       "The :field may only contain letters and numbers.",
+		"alpha_num_space": "The :field may only contain letters, numbers and spaces.",
+		"alpha_space":     "The :field may only contain letters and spaces.",
+		"email":           "The :field must be a valid email address.",
+		"url":             "The :field format is invalid.",
+		"json":            "The :field must be a valid JSON string.",
+		"cc":              "The :field must be a valid credit card number.",
+		"lte":             "The :field may not be greater than :max",
+		"gte":             "The :field may not be less than :min",
+		"lt":              "The :field must be less than :max",
+		"gt":              "The :field must be greater than :min",
+		"range":           "The :field must be between :min and :max.",
+		"contains":        "The :field format is invalid.",
+		"match":           "The :field format is invalid.",
+		"same":            "The :field format is invalid.",
+		"in":              "The selected :field is invalid.",
+		"not_in":          "The selected :field is invalid.",
+		"ean":             "The :field field is not valid ean13 code.",
+	},
+	"id": {
+		"required":        "Kolom :field wajib diisi.",
+		"numeric":         "Kolom :field harus berupa angka.",
+		"alpha":           "Kolom :field hanya boleh berisi huruf.",
+		"alpha_num":       "Kolom :field hanya boleh berisi huruf dan angka.",
+		"alpha_num_space": "Kolom :field hanya boleh berisi huruf, angka dan spasi.",
+		"alpha_space":     "Kolom :field hanya boleh berisi huruf dan spasi.",
+		"email":           "Kolom :field harus berupa alamat email yang valid.",
+		"url":             "Format :field tidak valid.",
+		"json":            "Kolom :field harus berupa JSON yang valid.",
+		"cc":              "Kolom :field harus berupa nomor kartu kredit yang valid.",
+		"lte":             "Kolom :field tidak boleh lebih besar dari :max",
+		"gte":             "Kolom :field tidak boleh lebih kecil dari :min",
+		"lt":              "Kolom :field harus lebih kecil dari :max",
+		"gt":              "Kolom :field harus lebih besar dari :min",
+		"range":           "Kolom :field harus di antara :min dan :max.",
+		"contains":        "Format :field tidak valid.",
+		"match":           "Format :field tidak valid.",
+		"same":            "Format :field tidak valid.",
+		"in":              "Pilihan :field tidak valid.",
+		"not_in":          "Pilihan :field tidak valid.",
+		"ean":             "Kolom :field bukan kode ean13 yang valid.",
+	},
+}}
+
+// RegisterCatalog register messages of the locale e.g en, id or en-us,
+// messages of the locale that already registered are overridden.
+func RegisterCatalog(locale string, c Catalog) {
+	catalogs.Lock()
+	defer catalogs.Unlock()
+
+	locale = strings.ToLower(locale)
+	if catalogs.locales[locale] == nil {
+		catalogs.locales[locale] = make(Catalog)
+	}
+
+	for code, m := range c {
+		catalogs.locales[locale][code] = m
+	}
+}
+
+// HasCatalog returns true if the locale has catalog registered.
+func HasCatalog(locale string) bool {
+	catalogs.RLock()
+	defer catalogs.RUnlock()
+
+	_, ok := catalogs.locales[strings.ToLower(locale)]
+	return ok
+}
+
+// Localize returns message of the failure from catalog of the locale,
+// custom messages or the code that not exists on the catalog are kept as is.
+func (f Failure) Localize(locale string) string {
+	if !f.catalog {
+		return f.Message
+	}
+
+	catalogs.RLock()
+	m, ok := catalogs.locales[strings.ToLower(locale)][f.Code]
+	catalogs.RUnlock()
+	if !ok {
+		return f.Message
+	}
+
+	field := f.field
+	if field == "" {
+		field = "%s"
+	}
+
+	// longer keys first, so :value is not replacing :values.
+	keys := make([]string, 0, len(f.Params))
+	for k := range f.Params {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+
+	r := []string{":field", field}
+	for _, k := range keys {
+		v := f.Params[k]
+		if l, ok := v.([]string); ok {
+			v = strings.Join(l, ", ")
+		}
+		r = append(r, ":"+k, fmt.Sprint(v))
+	}
+
+	return strings.NewReplacer(r...).Replace(m)
+}
+
+// ruleParams returns params of the failing tag.
+func ruleParams(t tag) map[string]interface{} {
+	if fn, ok := ValidatorParams[t.Name]; ok {
+		return fn(t.Param)
+	}
+
+	if t.Param != "" {
+		return map[string]interface{}{"value": t.Param}
+	}
+
+	return nil
+}
+
+func minParam(param string) map[string]interface{} {
+	return map[string]interface{}{"min": convert(param)}
+}
+
+func maxParam(param string) map[string]interface{} {
+	return map[string]interface{}{"max": convert(param)}
+}
+
+func rangeParam(param string) map[string]interface{} {
+	p := strings.Split(param, ",")
+	if len(p) != 2 {
+		return map[string]interface{}{"value": param}
+	}
+
+	return map[string]interface{}{"min": convert(p[0]), "max": convert(p[1])}
+}
+
+func patternParam(param string) map[string]interface{} {
+	return map[string]interface{}{"pattern": param}
+}
+
+func valuesParam(param string) map[string]interface{} {
+	return map[string]interface{}{"values": strings.Split(param, ",")}
+}
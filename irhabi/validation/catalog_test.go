@@ -0,0 +1,127 @@
+package validation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type catalogMember struct {
+	Age int `valid:"required|range:1,140"`
+}
+
+type catalogAccount struct {
+	Username string          `valid:"required"`
+	Password string          `valid:"required|gte:3"`
+	Members  []catalogMember `valid:"required"`
+}
+
+func (t catalogAccount) Validate() *Output {
+	o := &Output{Valid: true}
+	if len(t.Username) < 5 {
+		o.Valid = false
+		o.Failure("username.invalid", "username is not valid")
+	}
+
+	return o
+}
+
+func (t catalogAccount) Messages() map[string]string {
+	return map[string]string{
+		"password.gte":        "more length please",
+		"members.*.age.range": "invalid",
+	}
+}
+
+func TestValidationFailures(t *testing.T) {
+	type Member struct {
+		Name  string `valid:"required"`
+		Age   int    `valid:"gte:18"`
+		Level string `valid:"in:gold,silver"`
+	}
+
+	type Form struct {
+		Email   string   `valid:"email"`
+		Members []Member `valid:"required"`
+	}
+
+	v := New()
+	o := v.Struct(Form{Email: "notemail.com", Members: []Member{{Age: 17, Level: "bronze"}}})
+
+	f := o.Failures()
+	if assert.Len(t, f, 4) {
+		assert.Equal(t, "email", f["email"].Code)
+		assert.Nil(t, f["email"].Params)
+		assert.Equal(t, o.Message("email"), f["email"].Message)
+		assert.Equal(t, "required", f["members.0.name"].Code)
+		assert.Equal(t, "gte", f["members.0.age"].Code)
+		assert.Equal(t, map[string]interface{}{"min": 18}, f["members.0.age"].Params)
+		assert.Equal(t, "The age should be greater than 18", f["members.0.age"].Message)
+		assert.Equal(t, []string{"gold", "silver"}, f["members.0.level"].Params["values"])
+	}
+
+	f = o.Failures("en")
+	assert.Equal(t, "The age may not be less than 18", f["members.0.age"].Message)
+
+	f = o.Failures("id")
+	assert.Equal(t, "Kolom age tidak boleh lebih kecil dari 18", f["members.0.age"].Message)
+	assert.Equal(t, "Pilihan level tidak valid.", f["members.0.level"].Message)
+
+	// locale without catalog keeps the messages.
+	f = o.Failures("fr")
+	assert.Equal(t, "The age should be greater than 18", f["members.0.age"].Message)
+
+	RegisterCatalog("fr", Catalog{"gte": "Le champ :field doit être supérieur ou égal à :min."})
+	assert.True(t, HasCatalog("FR"))
+	f = o.Failures("fr")
+	assert.Equal(t, "Le champ age doit être supérieur ou égal à 18.", f["members.0.age"].Message)
+	assert.Equal(t, "The selected level is invalid.", f["members.0.level"].Message)
+
+	// custom messages and failures of request are never localized.
+	ore := v.Request(catalogAccount{Username: "use", Password: "ab", Members: []catalogMember{{150}}})
+	f = ore.Failures("id")
+	assert.Equal(t, "gte", f["password"].Code)
+	assert.Equal(t, map[string]interface{}{"min": 3}, f["password"].Params)
+	assert.Equal(t, "more length please", f["password"].Message)
+	assert.Equal(t, "range", f["members.0.age"].Code)
+	assert.Equal(t, "invalid", f["members.0.age"].Message)
+	assert.Equal(t, "invalid", f["username"].Code)
+	assert.Equal(t, "username is not valid", f["username"].Message)
+
+	f = SetError("email", "email is already registered").Failures("id")
+	assert.Equal(t, "invalid", f["email"].Code)
+	assert.Equal(t, "email is already registered", f["email"].Message)
+}
+
+func TestValidationComparisonMessages(t *testing.T) {
+	type Range struct {
+		Lte int `valid:"lte:10"`
+		Gte int `valid:"gte:20"`
+		Lt  int `valid:"lt:10"`
+		Gt  int `valid:"gt:20"`
+	}
+
+	o := New().Struct(Range{Lte: 11, Gte: 19, Lt: 10, Gt: 20})
+
+	// messages of the validator are kept as is, the catalog is corrected.
+	messages := map[string]string{
+		"lte": "The lte may not be greater than 10",
+		"gte": "The gte should be greater than 20",
+		"lt":  "The lt may not be greater than 10",
+		"gt":  "The gt should be greater than 20",
+	}
+	catalog := map[string]string{
+		"lte": "The lte may not be greater than 10",
+		"gte": "The gte may not be less than 20",
+		"lt":  "The lt must be less than 10",
+		"gt":  "The gt must be greater than 20",
+	}
+
+	f := o.Failures("en")
+	if assert.Len(t, f, 4) {
+		for field, m := range catalog {
+			assert.Equal(t, m, f[field].Message, field)
+			assert.Equal(t, messages[field], o.Message(field), field)
+		}
+	}
+}
@@ -16,6 +16,18 @@ type Output struct {
 	failureMessages map[string]string // failing error messages
 	customMessages  map[string]string // custom messages
 	failureKeys     []string
+	failures        map[string]*Failure // failing rules, keyed as failure messages
+	fieldFailures   map[string]*Failure // compiled failing rules
+}
+
+// Failure machine readable failure of a field, code is name of the failing rule
+// e.g {"code":"gte","params":{"min":18},"message":"The age should be greater than 18"}
+type Failure struct {
+	Code    string                 `json:"code"`
+	Params  map[string]interface{} `json:"params,omitempty"`
+	Message string                 `json:"message,omitempty"`
+	field   string                 // name of the field on message
+	catalog bool                   // message of the rule, that can be localized
 }
 
 // Messages is a map which contains all errors from validating a struct.
@@ -38,15 +50,71 @@ func (o *Output) Message(k ...string) string {
 	return vl
 }
 
-// Failure set an failure message as key and value
+// Failures returns failure of each failing field keyed as Messages,
+// the messages are localized with catalog of the locale if provided.
+func (o *Output) Failures(locale ...string) map[string]Failure {
+	res := make(map[string]Failure, len(o.fieldFailures))
+	for k, f := range o.fieldFailures {
+		x := *f
+		if len(locale) > 0 {
+			x.Message = x.Localize(locale[0])
+		}
+		res[k] = x
+	}
+
+	return res
+}
+
+// Failure set an failure message as key and value,
+// the extension of the key is the failure code e.g email.exists.
 func (o *Output) Failure(k string, e string) {
+	code := strings.TrimPrefix(filepath.Ext(k), ".")
+	if code == "" {
+		code = "invalid"
+	}
+
+	o.fail(k, &Failure{Code: code, Message: e})
+}
+
+// fail set the failure of the key.
+func (o *Output) fail(k string, f *Failure) {
 	if o.failureMessages == nil {
 		o.failureMessages = make(map[string]string)
 	}
 
+	if o.failures == nil {
+		o.failures = make(map[string]*Failure)
+	}
+
 	o.Valid = false
 	o.failureKeys = append(o.failureKeys, k)
-	o.failureMessages[k] = e
+	o.failureMessages[k] = f.Message
+	o.failures[k] = f
+}
+
+// merge set failures of the output with prefixed keys.
+func (o *Output) merge(prefix string, e *Output) {
+	for k, m := range e.failureMessages {
+		f := Failure{Code: "invalid"}
+		if x, ok := e.failures[k]; ok {
+			f = *x
+		}
+		f.Message = m
+
+		o.fail(prefix+k, &f)
+	}
+}
+
+// custom replace message of the failure with custom message.
+func (o *Output) custom(k string, e string) {
+	f := Failure{Code: "invalid"}
+	if x, ok := o.failures[k]; ok {
+		f = *x
+	}
+	f.Message = e
+	f.catalog = false
+
+	o.fail(k, &f)
 }
 
 // error wrapping up if validation failing.
@@ -54,14 +122,17 @@ func (o *Output) error() *Output {
 	o.applyCustomMessage()
 
 	res := make(map[string]string)
+	fs := make(map[string]*Failure)
 	for _, i := range o.failureKeys {
 		k := strings.TrimSuffix(i, filepath.Ext(i))
 		if _, ok := res[k]; !ok {
 			res[k] = o.failureMessages[i]
+			fs[k] = o.failures[i]
 		}
 	}
 
 	o.messages = res
+	o.fieldFailures = fs
 	return o
 }
 
@@ -69,7 +140,7 @@ func (o *Output) error() *Output {
 func (o *Output) applyCustomMessage() {
 	for i := range o.failureMessages {
 		if c := o.customMessages[i]; c != "" {
-			o.Failure(i, c)
+			o.custom(i, c)
 			continue
 		}
 
@@ -77,7 +148,7 @@ func (o *Output) applyCustomMessage() {
 			re := regexp.MustCompile("[^a-z.]")
 			ix := re.ReplaceAllString(i, "*")
 			if c := o.customMessages[ix]; c != "" {
-				o.Failure(i, c)
+				o.custom(i, c)
 			}
 		}
 	}
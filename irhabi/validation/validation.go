@@ -44,7 +44,7 @@ func (v *Validation) Field(value interface{}, tag string) *Output {
 	for _, t := range tags {
 		var e string
 		if r.Valid, e = t.Fn(value, t.Param); !r.Valid {
-			r.Failure(t.Name, e)
+			r.fail(t.Name, &Failure{Code: t.Name, Params: ruleParams(t), Message: e, catalog: true})
 			r.tag = t.Name
 			return r.error()
 		}
@@ -88,9 +88,7 @@ func (v *Validation) Struct(object interface{}) *Output {
 		if f.Kind() == reflect.Ptr && !f.IsNil() {
 			if oq, oqk := f.Interface().(Request); oqk {
 				if e := v.Request(oq); !e.Valid {
-					for j, k := range e.failureMessages {
-						res.Failure(fname+"."+j, k)
-					}
+					res.merge(fname+".", e)
 
 					continue
 				}
@@ -108,7 +106,10 @@ func (v *Validation) Struct(object interface{}) *Output {
 			}
 
 			if !r.Valid {
-				res.Failure(fname+"."+r.tag, fmt.Sprintf(r.Message(), fname))
+				f := *r.failures[r.tag]
+				f.Message = fmt.Sprintf(r.Message(), fname)
+				f.field = fname
+				res.fail(fname+"."+r.tag, &f)
 			}
 		}
 
@@ -121,9 +122,7 @@ func (v *Validation) Struct(object interface{}) *Output {
 			}
 
 			if !e.Valid {
-				for j, k := range e.failureMessages {
-					res.Failure(fname+"."+j, k)
-				}
+				res.merge(fname+".", e)
 			}
 		}
 
@@ -139,10 +138,7 @@ func (v *Validation) Struct(object interface{}) *Output {
 					}
 
 					if !e.Valid {
-						for j, k := range e.failureMessages {
-							kk := fmt.Sprintf("%s.%d.%s", fname, i, j)
-							res.Failure(kk, k)
-						}
+						res.merge(fmt.Sprintf("%s.%d.", fname, i), e)
 					}
 				}
 			}
@@ -169,18 +165,14 @@ func (v *Validation) Request(object Request) *Output {
 
 	if os := v.Struct(object); !os.Valid {
 		o.Valid = false
-		for x, y := range os.failureMessages {
-			o.Failure(x, y)
-		}
+		o.merge("", os)
 	}
 
 	if or := object.Validate(); or != nil && !or.Valid {
 		or.error()
 
 		o.Valid = false
-		for x, y := range or.failureMessages {
-			o.Failure(x, y)
-		}
+		o.merge("", or)
 	}
 
 	if !o.Valid {
@@ -138,7 +138,7 @@ func validJSON(value interface{}, _ string) (v bool, m string) {
 
 func validCc(value interface{}, _ string) (v bool, m string) {
 	if v = IsCreditCard(value); !v {
-		m = "The :attribute must be a valid credit card number."
+		m = "The %s must be a valid credit card number."
 	}
 	return
 }